@@ -0,0 +1,246 @@
+// Package analytics turns tracked detections into zone and line events:
+// entries and exits, directional line crossings, occupancy and dwell time.
+package analytics
+
+import (
+	"slices"
+	"time"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+	"github.com/zazamaza/yolo-object-detection-go/tracker"
+)
+
+// Zone is a polygonal area of the frame.
+type Zone struct {
+	Name    string
+	Polygon utils.Polygon
+	// Labels restricts the zone to these labels when not empty.
+	Labels []string
+}
+
+type EventType int
+
+const (
+	ZoneEnter EventType = iota
+	ZoneExit
+	LineCross
+)
+
+func (t EventType) String() string {
+	switch t {
+	case ZoneEnter:
+		return "enter"
+	case ZoneExit:
+		return "exit"
+	default:
+		return "cross"
+	}
+}
+
+// Event is something a track did in the frame at Time.
+type Event struct {
+	Type    EventType
+	TrackID int
+	Label   string
+	// Name is the zone or line the event refers to.
+	Name string
+	// Direction is set for line crossings.
+	Direction Direction
+	// Dwell is the time spent in the zone, set on exits.
+	Dwell time.Duration
+	Time  time.Time
+}
+
+// LineCount is the running number of crossings of a line.
+type LineCount struct {
+	LeftToRight int
+	RightToLeft int
+}
+
+// Report is the outcome of one frame.
+type Report struct {
+	Events []Event
+	// Occupancy is the number of tracks in every zone.
+	Occupancy map[string]int
+	// Tracks are the confirmed tracks of the frame.
+	Tracks []tracker.Track
+}
+
+// trackState is what the analyzer remembers of a track.
+type trackState struct {
+	label  string
+	anchor utils.Point
+	// entered holds the entry time of every zone the track is in.
+	entered map[string]time.Time
+	seen    bool
+}
+
+// Analyzer follows tracks through zones and across lines. It is not safe
+// for concurrent use.
+type Analyzer struct {
+	Zones []Zone
+	Lines []Line
+	// Tracker associates boxes across frames in Update.
+	Tracker tracker.ITracker
+	Anchor  Anchor
+
+	tracks map[int]*trackState
+	counts map[string]LineCount
+}
+
+// NewAnalyzer uses ByteTrack to give detections stable IDs.
+func NewAnalyzer(zones []Zone, lines []Line) *Analyzer {
+	return &Analyzer{
+		Zones:   zones,
+		Lines:   lines,
+		Tracker: tracker.NewByteTrack(),
+		Anchor:  AnchorBottomCenter,
+		tracks:  map[int]*trackState{},
+		counts:  map[string]LineCount{},
+	}
+}
+
+// Update tracks the detections of a frame taken at timestamp and returns
+// its events.
+func (a *Analyzer) Update(boxes []utils.BoundingBox, timestamp time.Time) Report {
+	return a.UpdateTracks(a.Tracker.Update(boxes), timestamp)
+}
+
+// UpdateTracks analyses tracks produced by any tracker. Only confirmed
+// tracks move; lost tracks keep their zones until they disappear, which
+// counts as leaving.
+func (a *Analyzer) UpdateTracks(tracks []tracker.Track, timestamp time.Time) Report {
+	report := Report{Occupancy: make(map[string]int, len(a.Zones))}
+	for _, zone := range a.Zones {
+		report.Occupancy[zone.Name] = 0
+	}
+
+	for _, state := range a.tracks {
+		state.seen = false
+	}
+
+	for _, track := range tracks {
+		state, known := a.tracks[track.ID]
+		if known {
+			state.seen = true
+		}
+		if track.State != tracker.Confirmed {
+			continue
+		}
+		report.Tracks = append(report.Tracks, track)
+
+		anchor := a.Anchor.point(&track.Box)
+		if !known {
+			state = &trackState{label: track.Box.Label, anchor: anchor, entered: map[string]time.Time{}, seen: true}
+			a.tracks[track.ID] = state
+		} else {
+			a.cross(&report, track.ID, state, anchor, timestamp)
+		}
+		state.label = track.Box.Label
+		state.anchor = anchor
+
+		for _, zone := range a.Zones {
+			if !matches(zone.Labels, state.label) {
+				continue
+			}
+			_, inside := state.entered[zone.Name]
+			switch contains := zone.Polygon.Contains(anchor); {
+			case contains && !inside:
+				state.entered[zone.Name] = timestamp
+				report.Events = append(report.Events, Event{
+					Type: ZoneEnter, TrackID: track.ID, Label: state.label, Name: zone.Name, Time: timestamp,
+				})
+			case !contains && inside:
+				report.Events = append(report.Events, a.exit(track.ID, state, zone.Name, timestamp))
+			}
+		}
+	}
+
+	for id, state := range a.tracks {
+		if state.seen {
+			continue
+		}
+		for _, zone := range a.Zones {
+			if _, inside := state.entered[zone.Name]; inside {
+				report.Events = append(report.Events, a.exit(id, state, zone.Name, timestamp))
+			}
+		}
+		delete(a.tracks, id)
+	}
+
+	for _, state := range a.tracks {
+		for name := range state.entered {
+			report.Occupancy[name]++
+		}
+	}
+
+	// Map iteration order is random; keep the events in a stable order.
+	slices.SortStableFunc(report.Events, func(x, y Event) int {
+		if x.TrackID != y.TrackID {
+			return x.TrackID - y.TrackID
+		}
+		return int(x.Type) - int(y.Type)
+	})
+	return report
+}
+
+// cross records the lines crossed by a track moving to anchor.
+func (a *Analyzer) cross(report *Report, id int, state *trackState, anchor utils.Point, timestamp time.Time) {
+	for i := range a.Lines {
+		line := &a.Lines[i]
+		if !matches(line.Labels, state.label) {
+			continue
+		}
+		direction, crossed := line.crossing(state.anchor, anchor)
+		if !crossed {
+			continue
+		}
+
+		count := a.counts[line.Name]
+		if direction == LeftToRight {
+			count.LeftToRight++
+		} else {
+			count.RightToLeft++
+		}
+		a.counts[line.Name] = count
+
+		report.Events = append(report.Events, Event{
+			Type: LineCross, TrackID: id, Label: state.label, Name: line.Name, Direction: direction, Time: timestamp,
+		})
+	}
+}
+
+func (a *Analyzer) exit(id int, state *trackState, zone string, timestamp time.Time) Event {
+	entered := state.entered[zone]
+	delete(state.entered, zone)
+	return Event{
+		Type: ZoneExit, TrackID: id, Label: state.label, Name: zone, Dwell: timestamp.Sub(entered), Time: timestamp,
+	}
+}
+
+// LineCounts returns the crossings of every line so far.
+func (a *Analyzer) LineCounts() map[string]LineCount {
+	counts := make(map[string]LineCount, len(a.Lines))
+	for _, line := range a.Lines {
+		counts[line.Name] = a.counts[line.Name]
+	}
+	return counts
+}
+
+// Dwell returns how long a track has been in a zone at timestamp, or zero
+// when it is not inside.
+func (a *Analyzer) Dwell(trackID int, zone string, timestamp time.Time) time.Duration {
+	state, ok := a.tracks[trackID]
+	if !ok {
+		return 0
+	}
+	entered, inside := state.entered[zone]
+	if !inside {
+		return 0
+	}
+	return timestamp.Sub(entered)
+}
+
+func matches(labels []string, label string) bool {
+	return len(labels) == 0 || slices.Contains(labels, label)
+}
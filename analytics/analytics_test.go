@@ -0,0 +1,154 @@
+package analytics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+	"github.com/zazamaza/yolo-object-detection-go/tracker"
+)
+
+var start = time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+func at(seconds int) time.Time {
+	return start.Add(time.Duration(seconds) * time.Second)
+}
+
+// person returns a confirmed track whose bottom centre is at x, y.
+func person(id int, x, y float32) tracker.Track {
+	return tracker.Track{
+		ID:    id,
+		State: tracker.Confirmed,
+		Box:   utils.BoundingBox{Label: "person", X1: x - 5, Y1: y - 20, X2: x + 5, Y2: y},
+	}
+}
+
+func square(x1, y1, x2, y2 float32) utils.Polygon {
+	return utils.Polygon{{X: x1, Y: y1}, {X: x2, Y: y1}, {X: x2, Y: y2}, {X: x1, Y: y2}}
+}
+
+func TestLineCrossing(t *testing.T) {
+	line := Line{A: utils.Point{X: 0, Y: 50}, B: utils.Point{X: 100, Y: 50}}
+
+	tests := []struct {
+		name      string
+		p, q      utils.Point
+		direction Direction
+		crossed   bool
+	}{
+		{"Downwards", utils.Point{X: 50, Y: 40}, utils.Point{X: 50, Y: 60}, LeftToRight, true},
+		{"Upwards", utils.Point{X: 50, Y: 60}, utils.Point{X: 50, Y: 40}, RightToLeft, true},
+		{"Same side", utils.Point{X: 50, Y: 40}, utils.Point{X: 60, Y: 45}, 0, false},
+		{"Beyond the end", utils.Point{X: 150, Y: 40}, utils.Point{X: 150, Y: 60}, 0, false},
+		{"Onto the line", utils.Point{X: 50, Y: 40}, utils.Point{X: 50, Y: 50}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			direction, crossed := line.crossing(tt.p, tt.q)
+			if crossed != tt.crossed || direction != tt.direction {
+				t.Errorf("expected %v %v, got %v %v", tt.direction, tt.crossed, direction, crossed)
+			}
+		})
+	}
+}
+
+func TestAnalyzerZones(t *testing.T) {
+	analyzer := NewAnalyzer([]Zone{{Name: "checkout", Polygon: square(0, 0, 100, 100)}}, nil)
+
+	report := analyzer.UpdateTracks([]tracker.Track{person(1, 150, 50)}, at(0))
+	if len(report.Events) != 0 || report.Occupancy["checkout"] != 0 {
+		t.Errorf("expected no events outside the zone, got %+v", report)
+	}
+
+	report = analyzer.UpdateTracks([]tracker.Track{person(1, 50, 50), person(2, 60, 60)}, at(1))
+	expected := []Event{
+		{Type: ZoneEnter, TrackID: 1, Label: "person", Name: "checkout", Time: at(1)},
+		{Type: ZoneEnter, TrackID: 2, Label: "person", Name: "checkout", Time: at(1)},
+	}
+	if !reflect.DeepEqual(report.Events, expected) {
+		t.Errorf("expected %+v, got %+v", expected, report.Events)
+	}
+	if report.Occupancy["checkout"] != 2 {
+		t.Errorf("expected occupancy 2, got %d", report.Occupancy["checkout"])
+	}
+
+	if dwell := analyzer.Dwell(1, "checkout", at(4)); dwell != 3*time.Second {
+		t.Errorf("expected dwell 3s, got %v", dwell)
+	}
+
+	// Track 1 walks out and track 2 disappears.
+	report = analyzer.UpdateTracks([]tracker.Track{person(1, 150, 50)}, at(6))
+	expected = []Event{
+		{Type: ZoneExit, TrackID: 1, Label: "person", Name: "checkout", Dwell: 5 * time.Second, Time: at(6)},
+		{Type: ZoneExit, TrackID: 2, Label: "person", Name: "checkout", Dwell: 5 * time.Second, Time: at(6)},
+	}
+	if !reflect.DeepEqual(report.Events, expected) {
+		t.Errorf("expected %+v, got %+v", expected, report.Events)
+	}
+	if report.Occupancy["checkout"] != 0 {
+		t.Errorf("expected empty zone, got %d", report.Occupancy["checkout"])
+	}
+}
+
+func TestAnalyzerLostTrackStaysInZone(t *testing.T) {
+	analyzer := NewAnalyzer([]Zone{{Name: "aisle", Polygon: square(0, 0, 100, 100)}}, nil)
+	analyzer.UpdateTracks([]tracker.Track{person(1, 50, 50)}, at(0))
+
+	lost := person(1, 50, 50)
+	lost.State = tracker.Lost
+	report := analyzer.UpdateTracks([]tracker.Track{lost}, at(1))
+	if len(report.Events) != 0 || report.Occupancy["aisle"] != 1 {
+		t.Errorf("expected the lost track to stay in the zone, got %+v", report)
+	}
+}
+
+func TestAnalyzerLabels(t *testing.T) {
+	analyzer := NewAnalyzer([]Zone{{Name: "lot", Polygon: square(0, 0, 100, 100), Labels: []string{"car"}}}, nil)
+
+	report := analyzer.UpdateTracks([]tracker.Track{person(1, 50, 50)}, at(0))
+	if len(report.Events) != 0 || report.Occupancy["lot"] != 0 {
+		t.Errorf("expected people to be ignored, got %+v", report)
+	}
+}
+
+func TestAnalyzerLines(t *testing.T) {
+	door := Line{Name: "door", A: utils.Point{X: 0, Y: 50}, B: utils.Point{X: 100, Y: 50}}
+	analyzer := NewAnalyzer(nil, []Line{door})
+
+	analyzer.UpdateTracks([]tracker.Track{person(1, 50, 40), person(2, 20, 70)}, at(0))
+	report := analyzer.UpdateTracks([]tracker.Track{person(1, 50, 60), person(2, 25, 45)}, at(1))
+
+	expected := []Event{
+		{Type: LineCross, TrackID: 1, Label: "person", Name: "door", Direction: LeftToRight, Time: at(1)},
+		{Type: LineCross, TrackID: 2, Label: "person", Name: "door", Direction: RightToLeft, Time: at(1)},
+	}
+	if !reflect.DeepEqual(report.Events, expected) {
+		t.Errorf("expected %+v, got %+v", expected, report.Events)
+	}
+
+	analyzer.UpdateTracks([]tracker.Track{person(1, 50, 70)}, at(2))
+	counts := analyzer.LineCounts()
+	if counts["door"] != (LineCount{LeftToRight: 1, RightToLeft: 1}) {
+		t.Errorf("expected one crossing each way, got %+v", counts["door"])
+	}
+}
+
+func TestAnalyzerUpdate(t *testing.T) {
+	analyzer := NewAnalyzer([]Zone{{Name: "entrance", Polygon: square(0, 0, 200, 200)}}, nil)
+
+	box := func(x float32) utils.BoundingBox {
+		return utils.BoundingBox{Label: "person", Confidence: 0.9, X1: x, Y1: 100, X2: x + 40, Y2: 180}
+	}
+	analyzer.Update([]utils.BoundingBox{box(200)}, at(0))
+	analyzer.Update([]utils.BoundingBox{box(185)}, at(1))
+	report := analyzer.Update([]utils.BoundingBox{box(170)}, at(2))
+
+	if len(report.Tracks) != 1 || report.Tracks[0].ID != 1 {
+		t.Fatalf("expected one associated track, got %+v", report.Tracks)
+	}
+	if len(report.Events) != 1 || report.Events[0].Type != ZoneEnter {
+		t.Errorf("expected the track to enter, got %+v", report.Events)
+	}
+}
@@ -0,0 +1,75 @@
+package analytics
+
+import (
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// Line is a directed counting line from A to B. Crossing directions are
+// seen from A looking towards B in image coordinates.
+type Line struct {
+	Name string
+	A, B utils.Point
+	// Labels restricts counting to these labels when not empty.
+	Labels []string
+}
+
+type Direction int
+
+const (
+	// LeftToRight crosses from the left of A->B to its right.
+	LeftToRight Direction = iota
+	// RightToLeft crosses from the right of A->B to its left.
+	RightToLeft
+)
+
+func (d Direction) String() string {
+	if d == LeftToRight {
+		return "left-to-right"
+	}
+	return "right-to-left"
+}
+
+// side returns the signed area of the triangle a, b, p: positive when p is
+// on the right of a->b with the y axis pointing down.
+func side(a, b, p utils.Point) float32 {
+	return (b.X-a.X)*(p.Y-a.Y) - (b.Y-a.Y)*(p.X-a.X)
+}
+
+// crossing reports whether the movement from p to q crosses the line and
+// in which direction. Touching the line counts as being on its left, so a
+// point resting on the line is not counted twice.
+func (l *Line) crossing(p, q utils.Point) (Direction, bool) {
+	before, after := side(l.A, l.B, p), side(l.A, l.B, q)
+	if (before > 0) == (after > 0) {
+		return 0, false
+	}
+
+	// The movement crosses the infinite line; check it happens between A
+	// and B.
+	ab, ba := side(p, q, l.A), side(p, q, l.B)
+	if ab > 0 && ba > 0 || ab < 0 && ba < 0 {
+		return 0, false
+	}
+
+	if after > 0 {
+		return LeftToRight, true
+	}
+	return RightToLeft, true
+}
+
+// Anchor is the point of a box tested against zones and lines.
+type Anchor int
+
+const (
+	// AnchorBottomCenter suits people and vehicles seen from above at an
+	// angle, whose feet or wheels touch the floor plan.
+	AnchorBottomCenter Anchor = iota
+	AnchorCenter
+)
+
+func (a Anchor) point(box *utils.BoundingBox) utils.Point {
+	if a == AnchorCenter {
+		return utils.Point{X: (box.X1 + box.X2) / 2, Y: (box.Y1 + box.Y2) / 2}
+	}
+	return utils.Point{X: (box.X1 + box.X2) / 2, Y: box.Y2}
+}
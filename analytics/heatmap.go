@@ -0,0 +1,288 @@
+package analytics
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+	"github.com/zazamaza/yolo-object-detection-go/tracker"
+)
+
+type HeatMode int
+
+const (
+	// HeatAnchor adds one to the cell under the anchor of every box.
+	HeatAnchor HeatMode = iota
+	// HeatBox adds one to every cell the box covers.
+	HeatBox
+)
+
+// TrajectoryPoint is the anchor of a track in one frame.
+type TrajectoryPoint struct {
+	X, Y float32
+	Time time.Time
+}
+
+// Heatmap accumulates where objects were seen on a grid over the frame
+// and the path of every track, in original image coordinates. It is not
+// safe for concurrent use.
+type Heatmap struct {
+	// Width and Height are the frame size.
+	Width, Height int
+	// CellSize is the grid resolution in pixels.
+	CellSize int
+	Mode     HeatMode
+	Anchor   Anchor
+	// Decay scales the grid before every frame, so old activity fades; 1
+	// keeps everything.
+	Decay float32
+	// MaxTrajectory caps the points kept per track; zero keeps all.
+	MaxTrajectory int
+	// MaxAge drops the trajectories of tracks not seen for this long, so
+	// a long-running stream does not keep every ID; zero keeps them all.
+	MaxAge time.Duration
+
+	grid         []float32
+	cols, rows   int
+	trajectories map[int][]TrajectoryPoint
+}
+
+func NewHeatmap(width, height, cellSize int) *Heatmap {
+	cellSize = max(cellSize, 1)
+	cols := (width + cellSize - 1) / cellSize
+	rows := (height + cellSize - 1) / cellSize
+	return &Heatmap{
+		Width:        width,
+		Height:       height,
+		CellSize:     cellSize,
+		Mode:         HeatAnchor,
+		Anchor:       AnchorBottomCenter,
+		Decay:        1,
+		MaxAge:       time.Minute,
+		grid:         make([]float32, cols*rows),
+		cols:         cols,
+		rows:         rows,
+		trajectories: map[int][]TrajectoryPoint{},
+	}
+}
+
+// AddBoxes accumulates the detections of a frame.
+func (h *Heatmap) AddBoxes(boxes []utils.BoundingBox) {
+	h.decay()
+	for i := range boxes {
+		h.heat(&boxes[i])
+	}
+}
+
+// AddTracks accumulates the confirmed tracks of a frame and extends their
+// trajectories. Trajectories of removed tracks and of tracks older than
+// MaxAge are dropped.
+func (h *Heatmap) AddTracks(tracks []tracker.Track, timestamp time.Time) {
+	h.decay()
+	for i := range tracks {
+		if tracks[i].State == tracker.Removed {
+			delete(h.trajectories, tracks[i].ID)
+		}
+		if tracks[i].State != tracker.Confirmed {
+			continue
+		}
+		h.heat(&tracks[i].Box)
+
+		anchor := h.Anchor.point(&tracks[i].Box)
+		trajectory := append(h.trajectories[tracks[i].ID], TrajectoryPoint{X: anchor.X, Y: anchor.Y, Time: timestamp})
+		if h.MaxTrajectory > 0 && len(trajectory) > h.MaxTrajectory {
+			trajectory = slices.Delete(trajectory, 0, len(trajectory)-h.MaxTrajectory)
+		}
+		h.trajectories[tracks[i].ID] = trajectory
+	}
+
+	if h.MaxAge > 0 {
+		maps.DeleteFunc(h.trajectories, func(_ int, trajectory []TrajectoryPoint) bool {
+			return timestamp.Sub(trajectory[len(trajectory)-1].Time) > h.MaxAge
+		})
+	}
+}
+
+func (h *Heatmap) decay() {
+	if h.Decay == 1 {
+		return
+	}
+	for i := range h.grid {
+		h.grid[i] *= h.Decay
+	}
+}
+
+func (h *Heatmap) heat(box *utils.BoundingBox) {
+	if h.Mode == HeatBox {
+		col1, row1 := h.cell(box.X1, box.Y1)
+		col2, row2 := h.cell(math.Nextafter32(box.X2, box.X1), math.Nextafter32(box.Y2, box.Y1))
+		for row := row1; row <= row2; row++ {
+			for col := col1; col <= col2; col++ {
+				h.grid[row*h.cols+col]++
+			}
+		}
+		return
+	}
+
+	anchor := h.Anchor.point(box)
+	col, row := h.cell(anchor.X, anchor.Y)
+	h.grid[row*h.cols+col]++
+}
+
+// cell returns the grid cell of a point, clamped to the grid.
+func (h *Heatmap) cell(x, y float32) (int, int) {
+	col := min(max(int(x)/h.CellSize, 0), h.cols-1)
+	row := min(max(int(y)/h.CellSize, 0), h.rows-1)
+	return col, row
+}
+
+// Grid returns a copy of the accumulated values in row-major order.
+func (h *Heatmap) Grid() (values []float32, cols, rows int) {
+	return slices.Clone(h.grid), h.cols, h.rows
+}
+
+// Trajectories returns a copy of the path of every track.
+func (h *Heatmap) Trajectories() map[int][]TrajectoryPoint {
+	trajectories := make(map[int][]TrajectoryPoint, len(h.trajectories))
+	for id, trajectory := range h.trajectories {
+		trajectories[id] = slices.Clone(trajectory)
+	}
+	return trajectories
+}
+
+// Reset clears the grid and the trajectories.
+func (h *Heatmap) Reset() {
+	clear(h.grid)
+	clear(h.trajectories)
+}
+
+// Image renders the heatmap at frame size: cold cells are transparent and
+// hot cells go from blue to red with growing opacity. Trajectories are
+// drawn on top in a colour per track, in ID order.
+func (h *Heatmap) Image() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, h.Width, h.Height))
+
+	highest := slices.Max(append([]float32{0}, h.grid...))
+	if highest > 0 {
+		for row := 0; row < h.rows; row++ {
+			for col := 0; col < h.cols; col++ {
+				value := h.grid[row*h.cols+col] / highest
+				if value <= 0 {
+					continue
+				}
+				cell := image.Rect(col*h.CellSize, row*h.CellSize, (col+1)*h.CellSize, (row+1)*h.CellSize)
+				draw.Draw(img, cell, image.NewUniform(heatColor(value)), image.Point{}, draw.Src)
+			}
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(h.trajectories)) {
+		trajectory := h.trajectories[id]
+		c := trackColor(id)
+		for i := 1; i < len(trajectory); i++ {
+			drawLine(img, trajectory[i-1], trajectory[i], c)
+		}
+	}
+	return img
+}
+
+// Overlay blends the heatmap over background, which should be a frame of
+// the same size.
+func (h *Heatmap) Overlay(background image.Image) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, h.Width, h.Height))
+	draw.Draw(img, img.Rect, background, background.Bounds().Min, draw.Src)
+	draw.Draw(img, img.Rect, h.Image(), image.Point{}, draw.Over)
+	return img
+}
+
+// WritePNG encodes the heatmap as a PNG, blended over background unless
+// it is nil.
+func (h *Heatmap) WritePNG(w io.Writer, background image.Image) error {
+	if background == nil {
+		return png.Encode(w, h.Image())
+	}
+	return png.Encode(w, h.Overlay(background))
+}
+
+// heatColor maps a value in 0-1 to a blue, cyan, green, yellow, red ramp
+// with opacity growing from 40% to 80%.
+func heatColor(value float32) color.NRGBA {
+	stops := [...][3]float32{{0, 0, 255}, {0, 255, 255}, {0, 255, 0}, {255, 255, 0}, {255, 0, 0}}
+	position := min(max(value, 0), 1) * float32(len(stops)-1)
+	i := min(int(position), len(stops)-2)
+	t := position - float32(i)
+
+	var rgb [3]uint8
+	for c := range rgb {
+		rgb[c] = uint8(stops[i][c] + t*(stops[i+1][c]-stops[i][c]) + 0.5)
+	}
+	return color.NRGBA{R: rgb[0], G: rgb[1], B: rgb[2], A: uint8(102 + value*102)}
+}
+
+// trackColor returns a stable saturated colour for a track ID.
+func trackColor(id int) color.NRGBA {
+	// The golden angle spreads consecutive IDs around the hue circle.
+	hue := math.Mod(float64(id)*137.508, 360)
+	sector := hue / 60
+	x := 1 - math.Abs(math.Mod(sector, 2)-1)
+	var r, g, b float64
+	switch int(sector) {
+	case 0:
+		r, g = 1, x
+	case 1:
+		r, g = x, 1
+	case 2:
+		g, b = 1, x
+	case 3:
+		g, b = x, 1
+	case 4:
+		r, b = x, 1
+	default:
+		r, b = 1, x
+	}
+	return color.NRGBA{R: uint8(r * 255), G: uint8(g * 255), B: uint8(b * 255), A: 255}
+}
+
+// drawLine draws a one pixel line with Bresenham's algorithm.
+func drawLine(img *image.NRGBA, from, to TrajectoryPoint, c color.NRGBA) {
+	x0, y0 := int(from.X), int(from.Y)
+	x1, y1 := int(to.X), int(to.Y)
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+
+	err := dx + dy
+	for {
+		if image.Pt(x0, y0).In(img.Rect) {
+			img.SetNRGBA(x0, y0, c)
+		}
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(x int) int {
+	return max(x, -x)
+}
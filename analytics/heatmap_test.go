@@ -0,0 +1,159 @@
+package analytics
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+	"github.com/zazamaza/yolo-object-detection-go/tracker"
+)
+
+func TestHeatmapAnchor(t *testing.T) {
+	heatmap := NewHeatmap(100, 50, 25)
+	heatmap.AddBoxes([]utils.BoundingBox{
+		{X1: 0, Y1: 0, X2: 20, Y2: 20},    // bottom centre (10, 20)
+		{X1: 60, Y1: 10, X2: 80, Y2: 49},  // bottom centre (70, 49)
+		{X1: 90, Y1: 30, X2: 110, Y2: 60}, // clamped to the last cell
+	})
+	heatmap.AddBoxes([]utils.BoundingBox{{X1: 0, Y1: 0, X2: 20, Y2: 20}})
+
+	values, cols, rows := heatmap.Grid()
+	if cols != 4 || rows != 2 {
+		t.Fatalf("expected a 4x2 grid, got %dx%d", cols, rows)
+	}
+	expected := []float32{
+		2, 0, 0, 0,
+		0, 0, 1, 1,
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
+
+func TestHeatmapBoxAndDecay(t *testing.T) {
+	heatmap := NewHeatmap(100, 50, 25)
+	heatmap.Mode = HeatBox
+	heatmap.Decay = 0.5
+
+	heatmap.AddBoxes([]utils.BoundingBox{{X1: 10, Y1: 0, X2: 50, Y2: 25}})
+	heatmap.AddBoxes(nil)
+
+	values, _, _ := heatmap.Grid()
+	expected := []float32{
+		0.5, 0.5, 0, 0,
+		0, 0, 0, 0,
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
+
+func TestHeatmapTrajectories(t *testing.T) {
+	heatmap := NewHeatmap(100, 100, 10)
+	heatmap.MaxTrajectory = 2
+
+	lost := person(2, 80, 80)
+	lost.State = tracker.Lost
+	for i := 0; i < 3; i++ {
+		heatmap.AddTracks([]tracker.Track{person(1, float32(10+10*i), 50), lost}, at(i))
+	}
+
+	trajectories := heatmap.Trajectories()
+	expected := map[int][]TrajectoryPoint{
+		1: {{X: 20, Y: 50, Time: at(1)}, {X: 30, Y: 50, Time: at(2)}},
+	}
+	if !reflect.DeepEqual(trajectories, expected) {
+		t.Errorf("expected %v, got %v", expected, trajectories)
+	}
+
+	heatmap.Reset()
+	if values, _, _ := heatmap.Grid(); values[5*10+1] != 0 || len(heatmap.Trajectories()) != 0 {
+		t.Errorf("expected Reset to clear the heatmap")
+	}
+}
+
+func TestHeatmapPrunesTrajectories(t *testing.T) {
+	heatmap := NewHeatmap(100, 100, 10)
+	heatmap.MaxAge = 2 * time.Second
+
+	removed := person(3, 50, 50)
+	heatmap.AddTracks([]tracker.Track{person(1, 10, 10), person(2, 20, 20), removed}, at(0))
+	removed.State = tracker.Removed
+	heatmap.AddTracks([]tracker.Track{person(1, 10, 20), removed}, at(1))
+	for i := 2; i <= 3; i++ {
+		heatmap.AddTracks([]tracker.Track{person(1, 10, float32(10+10*i))}, at(i))
+	}
+
+	trajectories := heatmap.Trajectories()
+	if len(trajectories) != 1 || len(trajectories[1]) != 4 {
+		t.Errorf("expected only the trajectory of track 1, got %v", trajectories)
+	}
+}
+
+func TestHeatmapTrajectoryOrder(t *testing.T) {
+	// Two tracks cross at (50, 50); the higher ID is drawn last.
+	for range 10 {
+		heatmap := NewHeatmap(100, 100, 10)
+		heatmap.AddTracks([]tracker.Track{person(7, 10, 50), person(3, 50, 10)}, at(0))
+		heatmap.AddTracks([]tracker.Track{person(7, 90, 50), person(3, 50, 90)}, at(1))
+
+		if got := heatmap.Image().NRGBAAt(50, 50); got != trackColor(7) {
+			t.Fatalf("expected the colour of track 7 on top, got %v", got)
+		}
+	}
+}
+
+func TestHeatmapPNG(t *testing.T) {
+	heatmap := NewHeatmap(40, 20, 10)
+	heatmap.AddBoxes([]utils.BoundingBox{
+		{X1: 0, Y1: 0, X2: 10, Y2: 9},
+		{X1: 0, Y1: 0, X2: 10, Y2: 9},
+		{X1: 20, Y1: 0, X2: 40, Y2: 9},
+	})
+
+	var buf bytes.Buffer
+	if err := heatmap.WritePNG(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Bounds() != image.Rect(0, 0, 40, 20) {
+		t.Fatalf("expected a 40x20 image, got %v", decoded.Bounds())
+	}
+
+	hot := color.NRGBAModel.Convert(decoded.At(5, 5)).(color.NRGBA)
+	if hot != (color.NRGBA{R: 255, A: 204}) {
+		t.Errorf("expected the hottest cell to be red, got %v", hot)
+	}
+	if cold := color.NRGBAModel.Convert(decoded.At(15, 15)).(color.NRGBA); cold.A != 0 {
+		t.Errorf("expected empty cells to be transparent, got %v", cold)
+	}
+
+	background := image.NewUniform(color.White)
+	overlay := heatmap.Overlay(image.NewNRGBA(image.Rect(0, 0, 40, 20)))
+	if overlay.NRGBAAt(15, 15).A != 0 {
+		t.Errorf("expected a transparent background to show through")
+	}
+	if err := heatmap.WritePNG(&buf, background); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHeatColor(t *testing.T) {
+	if c := heatColor(0); c.B != 255 || c.R != 0 {
+		t.Errorf("expected blue for cold, got %v", c)
+	}
+	if c := heatColor(0.5); c.G != 255 || c.R != 0 || c.B != 0 {
+		t.Errorf("expected green in the middle, got %v", c)
+	}
+	if trackColor(1) == trackColor(2) {
+		t.Errorf("expected distinct track colours")
+	}
+}
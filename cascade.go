@@ -0,0 +1,92 @@
+package yolo
+
+import (
+	"image"
+	"slices"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// ClassifiedBox is a detection with the secondary labels of its crop, best
+// first.
+type ClassifiedBox struct {
+	utils.BoundingBox
+	Classes []Classification
+}
+
+// CascadeOptions configures the classification stage of a Cascade.
+type CascadeOptions struct {
+	// Padding grows each crop by this fraction of the box width and height
+	// on every side, giving the classifier some context.
+	Padding float64
+	// TopK is the number of secondary labels attached to each detection;
+	// zero attaches every class.
+	TopK int
+	// Labels restricts classification to detections with these labels.
+	// Other detections are returned without secondary labels.
+	Labels []string
+}
+
+func DefaultCascadeOptions() CascadeOptions {
+	return CascadeOptions{
+		Padding: 0.1,
+		TopK:    1,
+	}
+}
+
+// Cascade detects objects and classifies each detection, e.g. a vehicle
+// detector followed by a make classifier.
+type Cascade struct {
+	Detector   *YOLO
+	Classifier *Classifier
+	Options    CascadeOptions
+}
+
+func NewCascade(detector *YOLO, classifier *Classifier, options CascadeOptions) *Cascade {
+	return &Cascade{
+		Detector:   detector,
+		Classifier: classifier,
+		Options:    options,
+	}
+}
+
+// Predict runs the detector on img with opts and classifies the
+// detections.
+func (c *Cascade) Predict(img image.Image, opts ...PredictOption) ([]ClassifiedBox, error) {
+	boxes, err := c.Detector.Predict(img, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return c.Classify(img, boxes)
+}
+
+// Classify crops boxes from img, batches the crops through the classifier
+// and attaches the results. The boxes can come from any Predict variant.
+func (c *Cascade) Classify(img image.Image, boxes []utils.BoundingBox) ([]ClassifiedBox, error) {
+	results := make([]ClassifiedBox, len(boxes))
+	crops := make([]image.Image, 0, len(boxes))
+	indices := make([]int, 0, len(boxes))
+
+	bounds := img.Bounds()
+	for i, box := range boxes {
+		results[i].BoundingBox = box
+		if len(c.Options.Labels) > 0 && !slices.Contains(c.Options.Labels, box.Label) {
+			continue
+		}
+		rect := box.PaddedRect(c.Options.Padding, image.Rectangle{Max: bounds.Size()})
+		if rect.Empty() {
+			continue
+		}
+		crops = append(crops, utils.SubImage(img, rect.Add(bounds.Min)))
+		indices = append(indices, i)
+	}
+
+	classes, err := c.Classifier.Classify(crops, c.Options.TopK)
+	if err != nil {
+		return nil, err
+	}
+	for i, index := range indices {
+		results[index].Classes = classes[i]
+	}
+	return results, nil
+}
@@ -0,0 +1,106 @@
+package yolo
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+
+	models "github.com/zazamaza/yolo-object-detection-go/internal/models"
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// classifierEngine scores every crop of a batch with classify, finding the
+// crops through the indices written by fakePreProcess.
+type classifierEngine struct {
+	fakeEngine
+	preProcess *fakePreProcess
+	imageSize  int
+	classify   func(image.Image) []float32
+	output     []float32
+	runs       int
+}
+
+func (e *classifierEngine) Run() error {
+	e.runs++
+	e.output = e.output[:0]
+	for b := range len(e.input) / e.imageSize {
+		index := int(e.input[b*e.imageSize])
+		if index < len(e.preProcess.images) {
+			e.output = append(e.output, e.classify(e.preProcess.images[index])...)
+		}
+	}
+	return nil
+}
+
+func (e *classifierEngine) GetOutput() []float32 { return e.output }
+
+func TestCascadeClassify(t *testing.T) {
+	// The frame is a sub-image, so boxes are relative to a non-zero origin.
+	frame := image.NewNRGBA(image.Rect(0, 0, 240, 140))
+	img := frame.SubImage(image.Rect(40, 20, 240, 140)).(*image.NRGBA)
+	boxes := []utils.BoundingBox{
+		{Label: "car", Confidence: 0.9, X1: 10, Y1: 10, X2: 50, Y2: 50},
+		{Label: "sign", ClassID: 1, Confidence: 0.8, X1: 60, Y1: 10, X2: 80, Y2: 30},
+		{Label: "car", Confidence: 0.7, X1: 100, Y1: 20, X2: 140, Y2: 60},
+		{Label: "car", Confidence: 0.6, X1: 150, Y1: 60, X2: 190, Y2: 110},
+	}
+	colors := []color.NRGBA{{R: 255, A: 255}, {R: 255, A: 255}, {G: 255, A: 255}, {B: 255, A: 255}}
+	for i, box := range boxes {
+		box.Translate(40, 20)
+		paint(frame, []utils.BoundingBox{box}, colors[i:i+1])
+	}
+
+	// The classifier names the colour at the centre of a crop.
+	const imageSize = 3 * 4 * 4
+	preProcess := &fakePreProcess{}
+	engine := &classifierEngine{
+		fakeEngine: fakeEngine{input: make([]float32, 2*imageSize)},
+		preProcess: preProcess,
+		imageSize:  imageSize,
+		classify: func(crop image.Image) []float32 {
+			bounds := crop.Bounds()
+			c := color.NRGBAModel.Convert(crop.At((bounds.Min.X+bounds.Max.X)/2, (bounds.Min.Y+bounds.Max.Y)/2)).(color.NRGBA)
+			return []float32{float32(c.R) / 255, float32(c.G) / 255, float32(c.B) / 255}
+		},
+	}
+	classifier := &Classifier{
+		preProcessor:  preProcess,
+		engine:        engine,
+		postProcessor: &models.ClassifyPostProcess{Classes: []string{"red", "green", "blue"}},
+		inputShape:    4,
+		batchSize:     2,
+	}
+	cascade := NewCascade(nil, classifier, CascadeOptions{Padding: 0.25, TopK: 1, Labels: []string{"car"}})
+
+	results, err := cascade.Classify(img, boxes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Three crops in batches of two take two runs.
+	if engine.runs != 2 {
+		t.Errorf("expected 2 runs, got %d", engine.runs)
+	}
+	expectedCrops := []image.Rectangle{image.Rect(40, 20, 100, 80), image.Rect(130, 30, 190, 90), image.Rect(180, 67, 240, 140)}
+	if !reflect.DeepEqual(preProcess.bounds, expectedCrops) {
+		t.Errorf("expected crops %v, got %v", expectedCrops, preProcess.bounds)
+	}
+
+	expected := []string{"red", "", "green", "blue"}
+	if len(results) != len(boxes) {
+		t.Fatalf("expected %d results, got %+v", len(boxes), results)
+	}
+	for i, result := range results {
+		if result.BoundingBox != boxes[i] {
+			t.Errorf("result %d: expected box %+v, got %+v", i, boxes[i], result.BoundingBox)
+		}
+		label := ""
+		if len(result.Classes) > 0 {
+			label = result.Classes[0].Label
+		}
+		if label != expected[i] || len(result.Classes) > 1 {
+			t.Errorf("result %d: expected %q, got %+v", i, expected[i], result.Classes)
+		}
+	}
+}
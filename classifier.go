@@ -0,0 +1,96 @@
+package yolo
+
+import (
+	"fmt"
+	"image"
+
+	engine "github.com/zazamaza/yolo-object-detection-go/internal/engine"
+	models "github.com/zazamaza/yolo-object-detection-go/internal/models"
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+type Classification = utils.Classification
+
+// Classifier is an image classification model, used on its own or as the
+// second stage of a Cascade.
+type Classifier struct {
+	preProcessor  models.IPreProcess
+	engine        engine.IEngine
+	postProcessor *models.ClassifyPostProcess
+	inputShape    int
+	batchSize     int
+}
+
+// NewYOLOClassifier loads a YOLO -cls export with a 224x224 input.
+func NewYOLOClassifier(modelPath string, classes []string) (*Classifier, error) {
+	configuration := models.NewClassifierConfiguration()
+	configuration.ModelPath = modelPath
+	configuration.Classes = classes
+	return NewClassifier(&configuration)
+}
+
+func NewClassifier(configuration *models.ClassifierConfiguration) (*Classifier, error) {
+	engine, err := engine.NewEngine(
+		configuration.ModelPath,
+		configuration.InputName,
+		configuration.InputShape,
+		[]engine.OutputSpec{
+			{Name: configuration.OutputName, Shape: configuration.OutputShape(), Type: engine.Float32},
+		},
+		engine.CPU,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	inputShape := int(configuration.InputShape[2])
+	return &Classifier{
+		preProcessor: &models.YOLOPreProcess{
+			InputShape:    inputShape,
+			ImageUtils:    &utils.ImageUtils{},
+			Letterbox:     configuration.Letterbox,
+			Normalization: configuration.Normalization,
+		},
+		engine: engine,
+		postProcessor: &models.ClassifyPostProcess{
+			Classes: configuration.Classes,
+			Softmax: configuration.Softmax,
+		},
+		inputShape: inputShape,
+		batchSize:  max(int(configuration.InputShape[0]), 1),
+	}, nil
+}
+
+// Classify returns the topK classes of every image, best first, running
+// the images through the engine in batches. A topK of zero returns every
+// class.
+func (c *Classifier) Classify(imgs []image.Image, topK int) ([][]Classification, error) {
+	imageSize := c.inputShape * c.inputShape * 3
+	classes := len(c.postProcessor.Classes)
+
+	results := make([][]Classification, 0, len(imgs))
+	input := make([]float32, imageSize*c.batchSize)
+	for start := 0; start < len(imgs); start += c.batchSize {
+		batch := imgs[start:min(start+c.batchSize, len(imgs))]
+		for b, img := range batch {
+			dst := input[b*imageSize : (b+1)*imageSize]
+			c.preProcessor.PreProcess(img, &dst)
+		}
+
+		c.engine.SetInput(&input)
+		err := c.engine.Run()
+		if err != nil {
+			return nil, fmt.Errorf("error running ORT session: %s", err)
+		}
+
+		output := c.engine.GetOutput()
+		for b := range batch {
+			results = append(results, c.postProcessor.PostProcess(output[b*classes:(b+1)*classes], topK))
+		}
+	}
+	return results, nil
+}
+
+func (c *Classifier) Destroy() {
+	c.engine.Destroy()
+}
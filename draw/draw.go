@@ -0,0 +1,316 @@
+// Package draw renders detections onto images: boxes, labels with
+// confidence, segmentation masks and keypoints, in colours that stay the
+// same for a class across frames and runs. It also anonymizes detected
+// regions for privacy.
+package draw
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// Keypoint is a detected point, such as a pose joint, in image
+// coordinates.
+type Keypoint struct {
+	X, Y       float32
+	Confidence float32
+}
+
+// Annotation is a detection with the optional outputs of segmentation and
+// pose models.
+type Annotation struct {
+	utils.BoundingBox
+	// Mask selects the pixels of the object by its alpha channel, in image
+	// coordinates; an *image.Alpha works. Nil draws no mask.
+	Mask      image.Image
+	Keypoints []Keypoint
+}
+
+// Options controls the rendering. The zero value draws 2 pixel boxes with
+// labels and confidences in the 7x13 bitmap font.
+type Options struct {
+	// LineWidth is the box outline width in pixels; zero means 2.
+	LineWidth int
+	// Face is the label font; nil uses the embedded 7x13 bitmap font.
+	Face font.Face
+	// FontScale enlarges the font by an integer factor; zero means 1.
+	FontScale int
+	// HideLabels draws boxes without labels.
+	HideLabels bool
+	// HideConfidence leaves the score out of the labels.
+	HideConfidence bool
+	// MaskOpacity is the opacity of mask fills in 0-1; zero means 0.5.
+	MaskOpacity float32
+	// KeypointRadius is the radius of keypoint dots; zero means 3.
+	KeypointRadius int
+	// KeypointThreshold hides keypoints with a lower confidence.
+	KeypointThreshold float32
+	// Skeleton joins pairs of keypoint indices with lines, such as the
+	// COCO pose limbs.
+	Skeleton [][2]int
+	// Palette replaces the default class colours, indexed by class ID.
+	Palette []color.NRGBA
+}
+
+// palette is the Ultralytics default colour cycle.
+var palette = []color.NRGBA{
+	{0xFF, 0x38, 0x38, 0xFF}, {0xFF, 0x9D, 0x97, 0xFF}, {0xFF, 0x70, 0x1F, 0xFF}, {0xFF, 0xB2, 0x1D, 0xFF},
+	{0xCF, 0xD2, 0x31, 0xFF}, {0x48, 0xF9, 0x0A, 0xFF}, {0x92, 0xCC, 0x17, 0xFF}, {0x3D, 0xDB, 0x86, 0xFF},
+	{0x1A, 0x93, 0x34, 0xFF}, {0x00, 0xD4, 0xBB, 0xFF}, {0x2C, 0x99, 0xA8, 0xFF}, {0x00, 0xC2, 0xFF, 0xFF},
+	{0x34, 0x45, 0x93, 0xFF}, {0x64, 0x73, 0xFF, 0xFF}, {0x00, 0x18, 0xEC, 0xFF}, {0x84, 0x38, 0xFF, 0xFF},
+	{0x52, 0x00, 0x85, 0xFF}, {0xCB, 0x38, 0xFF, 0xFF}, {0xFF, 0x95, 0xC8, 0xFF}, {0xFF, 0x37, 0xC7, 0xFF},
+}
+
+// ClassColor returns the default colour of a class ID.
+func ClassColor(classID int) color.NRGBA {
+	return palette[(classID%len(palette)+len(palette))%len(palette)]
+}
+
+func (o *Options) color(classID int) color.NRGBA {
+	if len(o.Palette) == 0 {
+		return ClassColor(classID)
+	}
+	return o.Palette[(classID%len(o.Palette)+len(o.Palette))%len(o.Palette)]
+}
+
+// Boxes returns a copy of img with boxes and their labels drawn on it.
+func Boxes(img image.Image, boxes []utils.BoundingBox, options Options) *image.NRGBA {
+	annotations := make([]Annotation, len(boxes))
+	for i, box := range boxes {
+		annotations[i] = Annotation{BoundingBox: box}
+	}
+	return Annotations(img, annotations, options)
+}
+
+// Annotations returns a copy of img with the annotations drawn on it.
+// Masks are drawn first, then boxes and keypoints, then labels, so labels
+// stay readable where objects overlap.
+func Annotations(img image.Image, annotations []Annotation, options Options) *image.NRGBA {
+	dst := image.NewNRGBA(img.Bounds())
+	draw.Draw(dst, dst.Rect, img, img.Bounds().Min, draw.Src)
+
+	for _, a := range annotations {
+		if a.Mask != nil {
+			drawMask(dst, a.Mask, options.color(a.ClassID), options.maskOpacity())
+		}
+	}
+	for _, a := range annotations {
+		c := options.color(a.ClassID)
+		drawBox(dst, a.BoundingBox, c, options.lineWidth())
+		drawKeypoints(dst, a.Keypoints, c, &options)
+	}
+	if !options.HideLabels {
+		for _, a := range annotations {
+			drawLabel(dst, a.BoundingBox, options.label(a.BoundingBox), options.color(a.ClassID), &options)
+		}
+	}
+	return dst
+}
+
+// WritePNG encodes img as PNG.
+func WritePNG(w io.Writer, img image.Image) error {
+	return png.Encode(w, img)
+}
+
+// WriteJPEG encodes img as JPEG with a quality of 1-100.
+func WriteJPEG(w io.Writer, img image.Image, quality int) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+}
+
+func (o *Options) lineWidth() int {
+	if o.LineWidth <= 0 {
+		return 2
+	}
+	return o.LineWidth
+}
+
+func (o *Options) fontScale() int {
+	return max(o.FontScale, 1)
+}
+
+func (o *Options) face() font.Face {
+	if o.Face == nil {
+		return basicfont.Face7x13
+	}
+	return o.Face
+}
+
+func (o *Options) maskOpacity() float32 {
+	if o.MaskOpacity <= 0 {
+		return 0.5
+	}
+	return min(o.MaskOpacity, 1)
+}
+
+func (o *Options) keypointRadius() int {
+	if o.KeypointRadius <= 0 {
+		return 3
+	}
+	return o.KeypointRadius
+}
+
+func (o *Options) label(box utils.BoundingBox) string {
+	if o.HideConfidence {
+		return box.Label
+	}
+	return fmt.Sprintf("%s %.2f", box.Label, box.Confidence)
+}
+
+// textColor returns black or white, whichever reads better on background.
+func textColor(background color.NRGBA) color.NRGBA {
+	luma := 299*int(background.R) + 587*int(background.G) + 114*int(background.B)
+	if luma > 150000 {
+		return color.NRGBA{A: 255}
+	}
+	return color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+}
+
+// drawBox draws the outline of box inside its edges.
+func drawBox(dst *image.NRGBA, box utils.BoundingBox, c color.NRGBA, width int) {
+	r := image.Rect(int(box.X1), int(box.Y1), int(box.X2+0.5), int(box.Y2+0.5))
+	width = min(width, (r.Dx()+1)/2, (r.Dy()+1)/2)
+	if width <= 0 {
+		return
+	}
+
+	src := image.NewUniform(c)
+	for _, edge := range []image.Rectangle{
+		image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+width),
+		image.Rect(r.Min.X, r.Max.Y-width, r.Max.X, r.Max.Y),
+		image.Rect(r.Min.X, r.Min.Y, r.Min.X+width, r.Max.Y),
+		image.Rect(r.Max.X-width, r.Min.Y, r.Max.X, r.Max.Y),
+	} {
+		draw.Draw(dst, edge.Intersect(dst.Rect), src, image.Point{}, draw.Src)
+	}
+}
+
+// drawMask blends c over the pixels selected by mask.
+func drawMask(dst *image.NRGBA, mask image.Image, c color.NRGBA, opacity float32) {
+	c.A = uint8(opacity*255 + 0.5)
+	r := mask.Bounds().Intersect(dst.Rect)
+	draw.DrawMask(dst, r, image.NewUniform(c), image.Point{}, mask, r.Min, draw.Over)
+}
+
+// drawLabel draws text on a filled band above the box, or inside its top
+// edge when there is no room above.
+func drawLabel(dst *image.NRGBA, box utils.BoundingBox, text string, c color.NRGBA, options *Options) {
+	if text == "" {
+		return
+	}
+
+	face := options.face()
+	scale := options.fontScale()
+	metrics := face.Metrics()
+	ascent := metrics.Ascent.Ceil()
+	textWidth := font.MeasureString(face, text).Ceil()
+	textHeight := ascent + metrics.Descent.Ceil()
+
+	// Render the text once at its native size and enlarge it by scale.
+	glyphs := image.NewAlpha(image.Rect(0, 0, textWidth, textHeight))
+	drawer := font.Drawer{Dst: glyphs, Src: image.Opaque, Face: face, Dot: fixed.P(0, ascent)}
+	drawer.DrawString(text)
+
+	const padding = 2
+	width := textWidth*scale + 2*padding
+	height := textHeight*scale + 2*padding
+	x, y := int(box.X1), int(box.Y1)-height
+	if y < dst.Rect.Min.Y {
+		y = int(box.Y1)
+	}
+	x = max(min(x, dst.Rect.Max.X-width), dst.Rect.Min.X)
+
+	band := image.Rect(x, y, x+width, y+height)
+	draw.Draw(dst, band.Intersect(dst.Rect), image.NewUniform(c), image.Point{}, draw.Src)
+
+	ink := textColor(c)
+	for gy := range textHeight * scale {
+		for gx := range textWidth * scale {
+			if glyphs.AlphaAt(gx/scale, gy/scale).A < 128 {
+				continue
+			}
+			px, py := x+padding+gx, y+padding+gy
+			if image.Pt(px, py).In(dst.Rect) {
+				dst.SetNRGBA(px, py, ink)
+			}
+		}
+	}
+}
+
+// drawKeypoints draws the skeleton lines and the dots of the keypoints
+// above the confidence threshold.
+func drawKeypoints(dst *image.NRGBA, keypoints []Keypoint, c color.NRGBA, options *Options) {
+	visible := func(i int) bool {
+		return i >= 0 && i < len(keypoints) && keypoints[i].Confidence >= options.KeypointThreshold
+	}
+
+	width := max(options.lineWidth()/2, 1)
+	for _, limb := range options.Skeleton {
+		if visible(limb[0]) && visible(limb[1]) {
+			from, to := keypoints[limb[0]], keypoints[limb[1]]
+			drawLine(dst, image.Pt(int(from.X), int(from.Y)), image.Pt(int(to.X), int(to.Y)), width, c)
+		}
+	}
+
+	radius := options.keypointRadius()
+	for i, k := range keypoints {
+		if visible(i) {
+			fillCircle(dst, image.Pt(int(k.X), int(k.Y)), radius, c)
+		}
+	}
+}
+
+// drawLine draws a line of the given width with Bresenham's algorithm,
+// stamping a dot at every step.
+func drawLine(dst *image.NRGBA, from, to image.Point, width int, c color.NRGBA) {
+	dx, dy := abs(to.X-from.X), -abs(to.Y-from.Y)
+	sx, sy := 1, 1
+	if from.X > to.X {
+		sx = -1
+	}
+	if from.Y > to.Y {
+		sy = -1
+	}
+
+	err := dx + dy
+	for p := from; ; {
+		fillCircle(dst, p, width/2, c)
+		if p == to {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			p.X += sx
+		}
+		if e2 <= dx {
+			err += dx
+			p.Y += sy
+		}
+	}
+}
+
+func fillCircle(dst *image.NRGBA, center image.Point, radius int, c color.NRGBA) {
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			p := center.Add(image.Pt(x, y))
+			if x*x+y*y <= radius*radius && p.In(dst.Rect) {
+				dst.SetNRGBA(p.X, p.Y, c)
+			}
+		}
+	}
+}
+
+func abs(x int) int {
+	return max(x, -x)
+}
@@ -0,0 +1,162 @@
+package draw
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+var gray = color.NRGBA{R: 50, G: 50, B: 50, A: 255}
+
+func grayImage(width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i := 0; i < len(img.Pix); i += 4 {
+		copy(img.Pix[i:], []uint8{gray.R, gray.G, gray.B, gray.A})
+	}
+	return img
+}
+
+func TestClassColor(t *testing.T) {
+	seen := map[color.NRGBA]bool{}
+	for id := range len(palette) {
+		c := ClassColor(id)
+		if seen[c] {
+			t.Errorf("class %d reuses colour %v", id, c)
+		}
+		seen[c] = true
+	}
+	if ClassColor(3) != ClassColor(3+len(palette)) || ClassColor(-1) != ClassColor(len(palette)-1) {
+		t.Error("expected colours to cycle through the palette")
+	}
+}
+
+func TestBoxes(t *testing.T) {
+	img := grayImage(100, 100)
+	box := utils.BoundingBox{Label: "car", ClassID: 2, Confidence: 0.87, X1: 20, Y1: 40, X2: 60, Y2: 80}
+
+	result := Boxes(img, []utils.BoundingBox{box}, Options{LineWidth: 3})
+	c := ClassColor(2)
+
+	if got := result.NRGBAAt(21, 60); got != c {
+		t.Errorf("expected the outline at the left edge, got %v", got)
+	}
+	if got := result.NRGBAAt(40, 78); got != c {
+		t.Errorf("expected a 3 pixel outline at the bottom edge, got %v", got)
+	}
+	if got := result.NRGBAAt(40, 60); got != gray {
+		t.Errorf("expected the inside untouched, got %v", got)
+	}
+	if img.NRGBAAt(21, 60) != gray {
+		t.Error("expected the input image to be left untouched")
+	}
+
+	// The label band sits above the box and holds some text pixels.
+	band := image.Rect(20, 40-13-4, 20+len("car 0.87")*7+4, 40)
+	ink := 0
+	for y := band.Min.Y; y < band.Max.Y; y++ {
+		for x := band.Min.X; x < band.Max.X; x++ {
+			switch result.NRGBAAt(x, y) {
+			case c:
+			case textColor(c):
+				ink++
+			default:
+				t.Fatalf("unexpected colour %v in the label at (%d, %d)", result.NRGBAAt(x, y), x, y)
+			}
+		}
+	}
+	if ink == 0 {
+		t.Error("expected label text")
+	}
+}
+
+func TestBoxesLabelOptions(t *testing.T) {
+	img := grayImage(100, 100)
+	box := utils.BoundingBox{Label: "car", Confidence: 0.87, X1: 20, Y1: 40, X2: 60, Y2: 80}
+
+	hidden := Boxes(img, []utils.BoundingBox{box}, Options{HideLabels: true})
+	if got := hidden.NRGBAAt(25, 35); got != gray {
+		t.Errorf("expected no label, got %v", got)
+	}
+
+	// Without room above, the label moves inside the box.
+	box.Y1, box.Y2 = 0, 50
+	short := Boxes(img, []utils.BoundingBox{box}, Options{HideConfidence: true, FontScale: 2})
+	labelWidth := 0
+	for x := 20; x < 100 && short.NRGBAAt(x, 10) != gray; x++ {
+		labelWidth++
+	}
+	if labelWidth != len("car")*7*2+4 {
+		t.Errorf("expected a label %d pixels wide, got %d", len("car")*7*2+4, labelWidth)
+	}
+}
+
+func TestAnnotationsMasksAndKeypoints(t *testing.T) {
+	img := grayImage(100, 100)
+	mask := image.NewAlpha(image.Rect(10, 10, 30, 30))
+	for i := range mask.Pix {
+		mask.Pix[i] = 255
+	}
+
+	annotation := Annotation{
+		BoundingBox: utils.BoundingBox{Label: "person", X1: 5, Y1: 5, X2: 95, Y2: 95},
+		Mask:        mask,
+		Keypoints: []Keypoint{
+			{X: 50, Y: 50, Confidence: 0.9},
+			{X: 70, Y: 50, Confidence: 0.9},
+			{X: 80, Y: 80, Confidence: 0.1},
+		},
+	}
+	options := Options{HideLabels: true, KeypointThreshold: 0.5, Skeleton: [][2]int{{0, 1}, {1, 2}}}
+	result := Annotations(img, []Annotation{annotation}, options)
+	c := ClassColor(0)
+
+	blended := result.NRGBAAt(20, 20)
+	if blended == gray || blended == c {
+		t.Errorf("expected the mask blended with the image, got %v", blended)
+	}
+	if got := result.NRGBAAt(40, 20); got != gray {
+		t.Errorf("expected no mask outside it, got %v", got)
+	}
+	if got := result.NRGBAAt(60, 50); got != c {
+		t.Errorf("expected a skeleton line between the keypoints, got %v", got)
+	}
+	if got := result.NRGBAAt(80, 80); got != gray {
+		t.Errorf("expected the low-confidence keypoint to be hidden, got %v", got)
+	}
+	if got := result.NRGBAAt(75, 65); got != gray {
+		t.Errorf("expected no line to the hidden keypoint, got %v", got)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	img := Boxes(grayImage(64, 48), []utils.BoundingBox{{Label: "dog", X1: 10, Y1: 20, X2: 40, Y2: 40}}, Options{})
+
+	var encoded bytes.Buffer
+	if err := WritePNG(&encoded, img); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := png.Decode(&encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Bounds() != img.Bounds() {
+		t.Errorf("expected PNG bounds %v, got %v", img.Bounds(), decoded.Bounds())
+	}
+
+	encoded.Reset()
+	if err := WriteJPEG(&encoded, img, 90); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err = jpeg.Decode(&encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Bounds() != img.Bounds() {
+		t.Errorf("expected JPEG bounds %v, got %v", img.Bounds(), decoded.Bounds())
+	}
+}
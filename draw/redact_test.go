@@ -0,0 +1,103 @@
+package draw
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// checkerImage alternates black and white pixels.
+func checkerImage(width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			v := uint8(255 * ((x + y) % 2))
+			img.SetNRGBA(x, y, color.NRGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+	return img
+}
+
+func TestRedactFillWithLabelsAndPadding(t *testing.T) {
+	img := grayImage(100, 100)
+	boxes := []utils.BoundingBox{
+		{Label: "face", X1: 20, Y1: 20, X2: 40, Y2: 40},
+		{Label: "car", X1: 60, Y1: 60, X2: 90, Y2: 90},
+	}
+	red := color.NRGBA{R: 255, A: 255}
+
+	result := Redact(img, boxes, RedactOptions{Mode: RedactFill, Labels: []string{"face"}, Padding: 0.25, Color: red})
+
+	for _, p := range []image.Point{{30, 30}, {15, 15}, {44, 44}} {
+		if got := result.NRGBAAt(p.X, p.Y); got != red {
+			t.Errorf("expected %v filled, got %v", p, got)
+		}
+	}
+	for _, p := range []image.Point{{14, 14}, {45, 45}, {75, 75}} {
+		if got := result.NRGBAAt(p.X, p.Y); got != gray {
+			t.Errorf("expected %v untouched, got %v", p, got)
+		}
+	}
+	if img.NRGBAAt(30, 30) != gray {
+		t.Error("expected the input image to be left untouched")
+	}
+}
+
+func TestRedactPixelate(t *testing.T) {
+	img := checkerImage(40, 40)
+	box := utils.BoundingBox{X1: 8, Y1: 8, X2: 24, Y2: 20}
+
+	result := Redact(img, []utils.BoundingBox{box}, RedactOptions{Mode: RedactPixelate, BlockSize: 4})
+
+	// Every 4x4 block of a checkerboard averages to mid gray.
+	for y := 8; y < 20; y++ {
+		for x := 8; x < 24; x++ {
+			if got := result.NRGBAAt(x, y); got.R != 128 || got.R != got.G || got.A != 255 {
+				t.Fatalf("expected mid gray at (%d, %d), got %v", x, y, got)
+			}
+		}
+	}
+	if result.NRGBAAt(7, 8) != img.NRGBAAt(7, 8) || result.NRGBAAt(24, 19) != img.NRGBAAt(24, 19) {
+		t.Error("expected pixels outside the box untouched")
+	}
+}
+
+func TestRedactBlur(t *testing.T) {
+	img := checkerImage(60, 60)
+	box := utils.BoundingBox{X1: 10, Y1: 10, X2: 50, Y2: 50}
+
+	result := Redact(img, []utils.BoundingBox{box}, RedactOptions{})
+
+	for y := 10; y < 50; y++ {
+		for x := 10; x < 50; x++ {
+			if got := result.NRGBAAt(x, y).R; got < 64 || got > 192 {
+				t.Fatalf("expected the checkerboard blurred at (%d, %d), got %d", x, y, got)
+			}
+		}
+	}
+	if result.NRGBAAt(9, 9) != img.NRGBAAt(9, 9) {
+		t.Error("expected pixels outside the box untouched")
+	}
+}
+
+func TestRedactMask(t *testing.T) {
+	img := grayImage(50, 50)
+	mask := image.NewAlpha(image.Rect(10, 10, 30, 30))
+	for y := 10; y < 30; y++ {
+		for x := 10; x < 20; x++ {
+			mask.SetAlpha(x, y, color.Alpha{A: 255})
+		}
+	}
+	annotation := Annotation{BoundingBox: utils.BoundingBox{X1: 10, Y1: 10, X2: 30, Y2: 30}, Mask: mask}
+
+	result := RedactAnnotations(img, []Annotation{annotation}, RedactOptions{Mode: RedactFill})
+
+	if got := result.NRGBAAt(15, 20); got != (color.NRGBA{A: 255}) {
+		t.Errorf("expected the masked pixel filled, got %v", got)
+	}
+	if got := result.NRGBAAt(25, 20); got != gray {
+		t.Errorf("expected the unmasked pixel inside the box untouched, got %v", got)
+	}
+}
@@ -0,0 +1,189 @@
+package yolo
+
+import (
+	"image"
+	"sync"
+	"time"
+
+	models "github.com/zazamaza/yolo-object-detection-go/internal/models"
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// Fusion is the way detections of several predictions of the same image
+// are combined.
+type Fusion int
+
+const (
+	// FusionWBF averages matching boxes weighted by confidence.
+	FusionWBF Fusion = iota
+	// FusionNMS keeps the most confident of matching boxes.
+	FusionNMS
+)
+
+// suppressor returns the fusion for detections pooled from the given
+// number of predictions.
+func (f Fusion) suppressor(predictions int) utils.ISuppressor {
+	if f == FusionNMS {
+		return &utils.GreedyNMS{}
+	}
+	return &utils.WeightedBoxesFusion{Models: predictions}
+}
+
+// EnsembleMember is one model of an ensemble.
+type EnsembleMember struct {
+	Model *YOLO
+	// LabelMap renames the model's labels into the ensemble label space.
+	// Labels missing from the map are kept; labels mapped to "" are
+	// dropped.
+	LabelMap map[string]string
+}
+
+// Ensemble runs several detectors on the same image and fuses their
+// detections in a unified label space. Members must be distinct models.
+type Ensemble struct {
+	Members []EnsembleMember
+	// Labels is the unified label space; a detection's ClassID is its
+	// label's index.
+	Labels       []string
+	Fusion       Fusion
+	IoUThreshold float32
+}
+
+// NewEnsemble builds the unified label space from the members' label maps
+// and fuses with WBF.
+func NewEnsemble(members ...EnsembleMember) *Ensemble {
+	ensemble := &Ensemble{
+		Members:      members,
+		Fusion:       FusionWBF,
+		IoUThreshold: 0.55,
+	}
+
+	seen := map[string]bool{}
+	for _, member := range members {
+		for _, label := range member.Model.classes {
+			if mapped, ok := member.LabelMap[label]; ok {
+				label = mapped
+			}
+			if label != "" && !seen[label] {
+				seen[label] = true
+				ensemble.Labels = append(ensemble.Labels, label)
+			}
+		}
+	}
+	return ensemble
+}
+
+// Predict runs every member concurrently and fuses the relabelled
+// detections. Members whose input shape matches the first member reuse its
+// preprocessed input. Options apply to every member; class options refer
+// to the unified labels. Afterwards each member's Timings are those of
+// its part of the prediction.
+func (e *Ensemble) Predict(img image.Image, opts ...PredictOption) ([]utils.BoundingBox, error) {
+	if len(e.Members) == 0 {
+		return []utils.BoundingBox{}, nil
+	}
+
+	options := e.Members[0].Model.defaults
+	options.apply(opts)
+
+	img, offset, ok := cropROI(img, options.ROI)
+	if !ok {
+		return []utils.BoundingBox{}, nil
+	}
+
+	// Members decode with the lowest threshold and no class filters, which
+	// are applied once labels are unified.
+	ensembleOptions := options.postProcessOptions()
+	memberOptions := models.PostProcessOptions{
+		ScoreThreshold: ensembleOptions.LowestThreshold(),
+		NMSThreshold:   options.NMSThreshold,
+		Agnostic:       options.AgnosticNMS,
+		SkipNMS:        options.RawCandidates,
+		ExclusionMasks: options.ExclusionMasks,
+	}
+	placements := []func(*utils.BoundingBox){translation(offset)}
+
+	// Every member's timings are those of this prediction; the shared input
+	// counts as the first member's preprocessing.
+	for _, member := range e.Members {
+		member.Model.timings = Timings{}
+	}
+
+	var shared *batchInput
+	first := e.Members[0].Model
+	for _, member := range e.Members[1:] {
+		if first.sharesInput(member.Model) {
+			started := time.Now()
+			shared = first.prepare([]image.Image{img}, nil)
+			first.timings.Preprocess += time.Since(started)
+			break
+		}
+	}
+
+	results := make([][]utils.BoundingBox, len(e.Members))
+	errs := make([]error, len(e.Members))
+	var wg sync.WaitGroup
+	for i, member := range e.Members {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			input := shared
+			if input == nil || !first.sharesInput(member.Model) {
+				started := time.Now()
+				input = member.Model.prepare([]image.Image{img}, member.Model.engine.InputData())
+				member.Model.timings.Preprocess += time.Since(started)
+			}
+			boxes, err := member.Model.infer(input, &memberOptions, placements)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			results[i] = e.relabel(boxes[0], member.LabelMap)
+		}()
+	}
+	wg.Wait()
+
+	boxes := []utils.BoundingBox{}
+	for i := range results {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		boxes = append(boxes, results[i]...)
+	}
+
+	boxes = models.FilterBoxes(boxes, ensembleOptions)
+	for i := range boxes {
+		placements[0](&boxes[i])
+	}
+	if !options.RawCandidates {
+		boxes = utils.SuppressBatched(e.Fusion.suppressor(len(e.Members)), boxes, e.IoUThreshold, options.AgnosticNMS)
+		// Fused boxes can move into a mask although none of their parts was.
+		boxes = utils.FilterExcluded(boxes, options.ExclusionMasks)
+		boxes = models.LimitDetections(boxes, ensembleOptions)
+	}
+	return boxes, nil
+}
+
+// relabel moves boxes into the unified label space, dropping labels mapped
+// to "".
+func (e *Ensemble) relabel(boxes []utils.BoundingBox, labelMap map[string]string) []utils.BoundingBox {
+	results := boxes[:0]
+	for _, box := range boxes {
+		if mapped, ok := labelMap[box.Label]; ok {
+			box.Label = mapped
+		}
+		classID := -1
+		for i, label := range e.Labels {
+			if label == box.Label {
+				classID = i
+				break
+			}
+		}
+		if box.Label == "" || classID < 0 {
+			continue
+		}
+		box.ClassID = classID
+		results = append(results, box)
+	}
+	return results
+}
@@ -0,0 +1,142 @@
+package yolo
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+func TestNewEnsembleLabels(t *testing.T) {
+	ensemble := NewEnsemble(
+		EnsembleMember{Model: &YOLO{classes: []string{"person", "car", "bus"}}},
+		EnsembleMember{
+			Model:    &YOLO{classes: []string{"pedestrian", "vehicle", "sign"}},
+			LabelMap: map[string]string{"pedestrian": "person", "vehicle": "car", "sign": ""},
+		},
+	)
+
+	expected := []string{"person", "car", "bus"}
+	if !reflect.DeepEqual(ensemble.Labels, expected) {
+		t.Errorf("expected labels %v, got %v", expected, ensemble.Labels)
+	}
+	if ensemble.Fusion != FusionWBF {
+		t.Errorf("expected WBF fusion by default")
+	}
+}
+
+func TestEnsembleRelabel(t *testing.T) {
+	ensemble := &Ensemble{Labels: []string{"person", "car"}}
+	labelMap := map[string]string{"vehicle": "car", "sign": ""}
+
+	boxes := []utils.BoundingBox{
+		{Label: "vehicle", ClassID: 0, Confidence: 0.9},
+		{Label: "sign", ClassID: 1, Confidence: 0.8},
+		{Label: "person", ClassID: 2, Confidence: 0.7},
+		{Label: "dog", ClassID: 3, Confidence: 0.6},
+	}
+	result := ensemble.relabel(boxes, labelMap)
+
+	expected := []utils.BoundingBox{
+		{Label: "car", ClassID: 1, Confidence: 0.9},
+		{Label: "person", ClassID: 0, Confidence: 0.7},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestFusionSuppressor(t *testing.T) {
+	if _, ok := FusionNMS.suppressor(3).(*utils.GreedyNMS); !ok {
+		t.Errorf("expected GreedyNMS for FusionNMS")
+	}
+	wbf, ok := FusionWBF.suppressor(3).(*utils.WeightedBoxesFusion)
+	if !ok || wbf.Models != 3 {
+		t.Errorf("expected WBF over 3 predictions, got %+v", wbf)
+	}
+}
+
+func TestEnsemblePredict(t *testing.T) {
+	red, blue, green, yellow := color.NRGBA{R: 255, A: 255}, color.NRGBA{B: 255, A: 255}, color.NRGBA{G: 255, A: 255}, color.NRGBA{R: 255, G: 255, A: 255}
+	img := image.NewNRGBA(image.Rect(0, 0, 200, 120))
+	general := []utils.BoundingBox{
+		{Label: "person", Confidence: 0.9, X1: 50, Y1: 30, X2: 80, Y2: 60},
+		{Label: "car", ClassID: 1, Confidence: 0.8, X1: 120, Y1: 50, X2: 150, Y2: 90},
+		// Inside the exclusion mask.
+		{Label: "person", Confidence: 0.95, X1: 150, Y1: 20, X2: 170, Y2: 40},
+	}
+	generalColors := []color.NRGBA{red, blue, yellow}
+	traffic := []utils.BoundingBox{
+		{Label: "pedestrian", Confidence: 0.7, X1: 50, Y1: 30, X2: 80, Y2: 60},
+		{Label: "vehicle", ClassID: 1, Confidence: 0.85, X1: 120, Y1: 50, X2: 150, Y2: 90},
+		{Label: "sign", ClassID: 2, Confidence: 0.99, X1: 90, Y1: 15, X2: 110, Y2: 25},
+	}
+	trafficColors := []color.NRGBA{red, blue, green}
+	paint(img, general, generalColors)
+	paint(img, traffic[2:], trafficColors[2:])
+
+	mask := ExclusionMask{Polygon: Polygon{{X: 145, Y: 15}, {X: 175, Y: 15}, {X: 175, Y: 45}, {X: 145, Y: 45}}}
+	expected := []utils.BoundingBox{
+		{Label: "person", Confidence: 0.9, X1: 50, Y1: 30, X2: 80, Y2: 60},
+		{Label: "car", ClassID: 1, Confidence: 0.85, X1: 120, Y1: 50, X2: 150, Y2: 90},
+	}
+
+	for _, shared := range []bool{true, false} {
+		generalPre, trafficPre := &fakePreProcess{}, &fakePreProcess{}
+		generalPost := &colorPostProcess{preProcess: generalPre, objects: general, colors: generalColors}
+		// A shared input is prepared by the first member only.
+		trafficPost := &colorPostProcess{preProcess: generalPre, objects: traffic, colors: trafficColors}
+		generalModel := fakeModel(generalPre, generalPost, 1)
+		trafficModel := fakeModel(trafficPre, trafficPost, 1)
+		if !shared {
+			trafficPost.preProcess = trafficPre
+			trafficModel.inputShape = 8
+			trafficModel.engine = &fakeEngine{input: make([]float32, 3*8*8)}
+		}
+		generalModel.classes = []string{"person", "car", "bus"}
+		trafficModel.classes = []string{"pedestrian", "vehicle", "sign"}
+
+		ensemble := NewEnsemble(
+			EnsembleMember{Model: generalModel},
+			EnsembleMember{
+				Model:    trafficModel,
+				LabelMap: map[string]string{"pedestrian": "person", "vehicle": "car", "sign": ""},
+			},
+		)
+		ensemble.Fusion = FusionNMS
+		// Timings left over from earlier predictions are reset.
+		stale := Timings{Preprocess: time.Hour, Inference: time.Hour, Postprocess: time.Hour}
+		generalModel.timings, trafficModel.timings = stale, stale
+
+		boxes, err := ensemble.Predict(img, WithROI(image.Rect(20, 10, 180, 110)), WithExclusionMasks(mask))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(boxes, expected) {
+			t.Errorf("shared %v: expected %+v, got %+v", shared, expected, boxes)
+		}
+		trafficPrepared := 1
+		if shared {
+			trafficPrepared = 0
+		}
+		if len(generalPre.images) != 1 || len(trafficPre.images) != trafficPrepared {
+			t.Errorf("shared %v: expected the input prepared once per distinct shape, got %d and %d",
+				shared, len(generalPre.images), len(trafficPre.images))
+		}
+		if generalPost.excluded != 1 || trafficPost.excluded != 0 {
+			t.Errorf("shared %v: expected the masked person dropped by its member, got %d and %d",
+				shared, generalPost.excluded, trafficPost.excluded)
+		}
+		for _, model := range []*YOLO{generalModel, trafficModel} {
+			if timings := model.Timings(); timings.Total() >= time.Hour {
+				t.Errorf("shared %v: expected timings of this prediction, got %+v", shared, timings)
+			}
+		}
+		if shared && trafficModel.Timings().Preprocess != 0 {
+			t.Errorf("expected the shared input counted for the first member only, got %v", trafficModel.Timings().Preprocess)
+		}
+	}
+}
@@ -37,7 +37,7 @@ func main() {
 		fmt.Printf("Error loading input image: %s\n", e)
 	}
 
-	boxes, err := model.Predict(img, 0.2, 0.5) //scoreThreshold: 0.2, nmsThreshold: 0.5
+	boxes, err := model.Predict(img, yolo.WithScoreThreshold(0.2), yolo.WithNMSThreshold(0.5))
 	if err != nil {
 		fmt.Printf("Error %s \n", err)
 	}
@@ -0,0 +1,220 @@
+// Package export saves detected objects as image crops for building
+// datasets from production traffic.
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// ManifestName is the file in the export directory that lists every crop.
+const ManifestName = "manifest.jsonl"
+
+// Format is the image format crops are written in.
+type Format int
+
+const (
+	FormatJPEG Format = iota
+	FormatPNG
+)
+
+func (f Format) extension() string {
+	if f == FormatPNG {
+		return ".png"
+	}
+	return ".jpg"
+}
+
+// CropOptions controls which detections are exported and how they are
+// cropped.
+type CropOptions struct {
+	// Padding grows each box by this fraction of its width and height on
+	// every side.
+	Padding float64
+	// Square expands each padded box to a square around its centre,
+	// shifted to stay inside the image when it fits.
+	Square bool
+	// Labels limits export to these labels; empty exports everything.
+	Labels []string
+	// MinSize skips crops narrower or shorter than this many pixels.
+	MinSize int
+	Format  Format
+	// Quality is the JPEG quality; zero means 90.
+	Quality int
+}
+
+// ManifestEntry links one crop back to its source image. Paths are
+// relative to the export directory, except Source which is stored as
+// given.
+type ManifestEntry struct {
+	Crop       string  `json:"crop"`
+	Source     string  `json:"source"`
+	Label      string  `json:"label"`
+	ClassID    int     `json:"class_id"`
+	Confidence float32 `json:"confidence"`
+	// Box is the detection as x1, y1, x2, y2 in source image pixels.
+	Box [4]float32 `json:"box"`
+	// Rect is the cropped region as x1, y1, x2, y2 in source image pixels.
+	Rect [4]int `json:"rect"`
+}
+
+// Exporter writes crops to one directory per label and appends an entry
+// per crop to the manifest. It is not safe for concurrent use.
+type Exporter struct {
+	Dir     string
+	Options CropOptions
+
+	manifest *os.File
+	encoder  *json.Encoder
+}
+
+// NewExporter creates dir if needed and opens its manifest for appending,
+// so several runs can add to the same dataset.
+func NewExporter(dir string, options CropOptions) (*Exporter, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating %s: %w", dir, err)
+	}
+	manifest, err := os.OpenFile(filepath.Join(dir, ManifestName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening manifest: %w", err)
+	}
+	return &Exporter{Dir: dir, Options: options, manifest: manifest, encoder: json.NewEncoder(manifest)}, nil
+}
+
+// Export crops the selected boxes out of img, the decoded image at source,
+// and returns the manifest entries it wrote. Existing crops are never
+// overwritten.
+func (e *Exporter) Export(source string, img image.Image, boxes []utils.BoundingBox) ([]ManifestEntry, error) {
+	stem := strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+	entries := []ManifestEntry{}
+
+	for i, box := range boxes {
+		if len(e.Options.Labels) > 0 && !slices.Contains(e.Options.Labels, box.Label) {
+			continue
+		}
+		rect := CropRect(&box, e.Options.Padding, e.Options.Square, img.Bounds())
+		if rect.Empty() || rect.Dx() < e.Options.MinSize || rect.Dy() < e.Options.MinSize {
+			continue
+		}
+
+		crop, err := e.writeCrop(utils.SubImage(img, rect), labelDir(box.Label), fmt.Sprintf("%s_%d", stem, i))
+		if err != nil {
+			return entries, err
+		}
+
+		entry := ManifestEntry{
+			Crop:       filepath.ToSlash(crop),
+			Source:     source,
+			Label:      box.Label,
+			ClassID:    box.ClassID,
+			Confidence: box.Confidence,
+			Box:        [4]float32{box.X1, box.Y1, box.X2, box.Y2},
+			Rect:       [4]int{rect.Min.X, rect.Min.Y, rect.Max.X, rect.Max.Y},
+		}
+		if err := e.encoder.Encode(&entry); err != nil {
+			return entries, fmt.Errorf("error writing manifest: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Close closes the manifest.
+func (e *Exporter) Close() error {
+	return e.manifest.Close()
+}
+
+// writeCrop encodes crop into dir under the first free name starting with
+// name and returns its path relative to the export directory.
+func (e *Exporter) writeCrop(crop image.Image, dir, name string) (string, error) {
+	if err := os.MkdirAll(filepath.Join(e.Dir, dir), 0o755); err != nil {
+		return "", fmt.Errorf("error creating %s: %w", dir, err)
+	}
+
+	extension := e.Options.Format.extension()
+	for n := 0; ; n++ {
+		path := filepath.Join(dir, name+extension)
+		if n > 0 {
+			path = filepath.Join(dir, fmt.Sprintf("%s-%d%s", name, n, extension))
+		}
+
+		f, err := os.OpenFile(filepath.Join(e.Dir, path), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("error creating %s: %w", path, err)
+		}
+
+		err = e.encode(f, crop)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return "", fmt.Errorf("error writing %s: %w", path, err)
+		}
+		return path, nil
+	}
+}
+
+func (e *Exporter) encode(f *os.File, crop image.Image) error {
+	if e.Options.Format == FormatPNG {
+		return png.Encode(f, crop)
+	}
+	quality := e.Options.Quality
+	if quality <= 0 {
+		quality = 90
+	}
+	return jpeg.Encode(f, crop, &jpeg.Options{Quality: quality})
+}
+
+// unbounded is large enough that clamping to it changes nothing.
+var unbounded = image.Rect(-1<<30, -1<<30, 1<<30, 1<<30)
+
+// CropRect returns the region of box grown by padding and, with square,
+// expanded to a square, clamped to bounds.
+func CropRect(box *utils.BoundingBox, padding float64, square bool, bounds image.Rectangle) image.Rectangle {
+	rect := box.PaddedRect(padding, unbounded)
+	if !square {
+		return rect.Intersect(bounds)
+	}
+
+	side := max(rect.Dx(), rect.Dy())
+	minX := rect.Min.X - (side-rect.Dx())/2
+	minY := rect.Min.Y - (side-rect.Dy())/2
+	// Keep the square inside the image along each axis it fits in.
+	if side <= bounds.Dx() {
+		minX = min(max(minX, bounds.Min.X), bounds.Max.X-side)
+	}
+	if side <= bounds.Dy() {
+		minY = min(max(minY, bounds.Min.Y), bounds.Max.Y-side)
+	}
+	return image.Rect(minX, minY, minX+side, minY+side).Intersect(bounds)
+}
+
+// labelDir turns a label into a directory name, replacing characters that
+// are unsafe in paths.
+func labelDir(label string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, label)
+	if name == "" {
+		return "unlabeled"
+	}
+	return name
+}
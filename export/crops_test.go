@@ -0,0 +1,121 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+func TestCropRect(t *testing.T) {
+	bounds := image.Rect(0, 0, 100, 80)
+	tests := []struct {
+		name     string
+		box      utils.BoundingBox
+		padding  float64
+		square   bool
+		expected image.Rectangle
+	}{
+		{name: "Plain", box: utils.BoundingBox{X1: 10.5, Y1: 20, X2: 30, Y2: 40.2}, expected: image.Rect(10, 20, 30, 41)},
+		{name: "Padded", box: utils.BoundingBox{X1: 20, Y1: 20, X2: 40, Y2: 30}, padding: 0.5, expected: image.Rect(10, 15, 50, 35)},
+		{name: "Padding clamped", box: utils.BoundingBox{X1: 0, Y1: 0, X2: 20, Y2: 20}, padding: 0.5, expected: image.Rect(0, 0, 30, 30)},
+		{name: "Square", box: utils.BoundingBox{X1: 40, Y1: 30, X2: 60, Y2: 40}, square: true, expected: image.Rect(40, 25, 60, 45)},
+		{name: "Square shifted inside", box: utils.BoundingBox{X1: 90, Y1: 0, X2: 100, Y2: 30}, square: true, expected: image.Rect(70, 0, 100, 30)},
+		{name: "Square larger than image", box: utils.BoundingBox{X1: 0, Y1: 10, X2: 100, Y2: 20}, square: true, expected: image.Rect(0, 0, 100, 65)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CropRect(&tt.box, tt.padding, tt.square, bounds); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func readManifest(t *testing.T, dir string) []ManifestEntry {
+	t.Helper()
+	f, err := os.Open(filepath.Join(dir, ManifestName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var entries []ManifestEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry ManifestEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestExporter(t *testing.T) {
+	dir := t.TempDir()
+	img := image.NewNRGBA(image.Rect(0, 0, 100, 80))
+	img.SetNRGBA(25, 25, color.NRGBA{R: 255, A: 255})
+	boxes := []utils.BoundingBox{
+		{Label: "traffic light", ClassID: 9, Confidence: 0.8, X1: 20, Y1: 20, X2: 30, Y2: 40},
+		{Label: "car", ClassID: 2, Confidence: 0.9, X1: 50, Y1: 40, X2: 90, Y2: 70},
+		{Label: "person", X1: 0, Y1: 0, X2: 10, Y2: 10},
+	}
+
+	exporter, err := NewExporter(dir, CropOptions{Labels: []string{"traffic light", "car"}, Format: FormatPNG})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := exporter.Export("frames/cam1/0001.jpg", img, boxes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Exporting the same frame again must not overwrite the crops.
+	if _, err := exporter.Export("frames/cam1/0001.jpg", img, boxes[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []ManifestEntry{
+		{Crop: "traffic_light/0001_0.png", Source: "frames/cam1/0001.jpg", Label: "traffic light", ClassID: 9, Confidence: 0.8, Box: [4]float32{20, 20, 30, 40}, Rect: [4]int{20, 20, 30, 40}},
+		{Crop: "car/0001_1.png", Source: "frames/cam1/0001.jpg", Label: "car", ClassID: 2, Confidence: 0.9, Box: [4]float32{50, 40, 90, 70}, Rect: [4]int{50, 40, 90, 70}},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %+v", len(expected), entries)
+	}
+	for i := range expected {
+		if entries[i] != expected[i] {
+			t.Errorf("entry %d: expected %+v, got %+v", i, expected[i], entries[i])
+		}
+	}
+
+	manifest := readManifest(t, dir)
+	if len(manifest) != 3 || manifest[0] != expected[0] || manifest[2].Crop != "traffic_light/0001_0-1.png" {
+		t.Errorf("unexpected manifest %+v", manifest)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "traffic_light", "0001_0.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	crop, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crop.Bounds().Size() != image.Pt(10, 20) {
+		t.Errorf("expected a 10x20 crop, got %v", crop.Bounds().Size())
+	}
+	if r, _, _, _ := crop.At(crop.Bounds().Min.X+5, crop.Bounds().Min.Y+5).RGBA(); r != 0xffff {
+		t.Errorf("expected the red pixel in the crop, got %d", r)
+	}
+}
@@ -0,0 +1,133 @@
+package yolo
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	models "github.com/zazamaza/yolo-object-detection-go/internal/models"
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// fakeEngine echoes its input tensor as the only output.
+type fakeEngine struct {
+	input []float32
+}
+
+func (e *fakeEngine) SetInput(input *[]float32) { copy(e.input, *input) }
+func (e *fakeEngine) InputData() []float32      { return e.input }
+func (e *fakeEngine) GetOutput() []float32      { return e.input }
+func (e *fakeEngine) GetOutputs() [][]float32   { return [][]float32{e.input} }
+func (e *fakeEngine) Run() error                { return nil }
+func (e *fakeEngine) Destroy()                  {}
+
+// fakePreProcess records every image it is given and writes the index of
+// the image into the first input value, so post-processing can find it.
+type fakePreProcess struct {
+	images []image.Image
+	bounds []image.Rectangle
+}
+
+func (p *fakePreProcess) PreProcess(img image.Image, dst *[]float32) utils.Transform {
+	(*dst)[0] = float32(len(p.images))
+	p.images = append(p.images, img)
+	p.bounds = append(p.bounds, img.Bounds())
+	return utils.Transform{ScaleX: 1, ScaleY: 1}
+}
+
+// fakePostProcess detects the part of every object that is visible in the
+// image, in image coordinates, scaling its confidence by the visible
+// fraction.
+type fakePostProcess struct {
+	preProcess *fakePreProcess
+	objects    []utils.BoundingBox
+}
+
+func (p *fakePostProcess) PostProcess(outputs [][]float32,
+	originalWidth, originalHeight int,
+	transform utils.Transform,
+	options *models.PostProcessOptions,
+) []utils.BoundingBox {
+	bounds := p.preProcess.bounds[int(outputs[0][0])]
+	boxes := []utils.BoundingBox{}
+	for _, object := range p.objects {
+		visible := object
+		visible.X1, visible.Y1 = max(visible.X1, float32(bounds.Min.X)), max(visible.Y1, float32(bounds.Min.Y))
+		visible.X2, visible.Y2 = min(visible.X2, float32(bounds.Max.X)), min(visible.Y2, float32(bounds.Max.Y))
+		if visible.Area() <= 0 {
+			continue
+		}
+		visible.Confidence *= visible.Area() / object.Area()
+		visible.Translate(-float32(bounds.Min.X), -float32(bounds.Min.Y))
+		boxes = append(boxes, visible)
+	}
+	return boxes
+}
+
+// colorPostProcess detects every object by finding the pixels of its
+// colour in the image, in image coordinates. Like the real post-processors
+// it drops candidates excluded by the masks where Placement puts them.
+type colorPostProcess struct {
+	preProcess *fakePreProcess
+	objects    []utils.BoundingBox
+	colors     []color.NRGBA
+	// excluded counts the candidates dropped by the masks.
+	excluded int
+}
+
+func (p *colorPostProcess) PostProcess(outputs [][]float32,
+	originalWidth, originalHeight int,
+	transform utils.Transform,
+	options *models.PostProcessOptions,
+) []utils.BoundingBox {
+	img := p.preProcess.images[int(outputs[0][0])]
+	bounds := img.Bounds()
+	boxes := []utils.BoundingBox{}
+	for i, object := range p.objects {
+		found := image.Rectangle{}
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				if color.NRGBAModel.Convert(img.At(x, y)) == p.colors[i] {
+					found = found.Union(image.Rect(x, y, x+1, y+1))
+				}
+			}
+		}
+		if found.Empty() {
+			continue
+		}
+		found = found.Sub(bounds.Min)
+		object.X1, object.Y1 = float32(found.Min.X), float32(found.Min.Y)
+		object.X2, object.Y2 = float32(found.Max.X), float32(found.Max.Y)
+
+		placed := object
+		if options.Placement != nil {
+			options.Placement(&placed)
+		}
+		if utils.Excluded(&placed, options.ExclusionMasks) {
+			p.excluded++
+			continue
+		}
+		boxes = append(boxes, object)
+	}
+	return boxes
+}
+
+// paint fills the boxes of objects in img with their colours.
+func paint(img *image.NRGBA, objects []utils.BoundingBox, colors []color.NRGBA) {
+	for i, object := range objects {
+		rect := image.Rect(int(object.X1), int(object.Y1), int(object.X2), int(object.Y2))
+		draw.Draw(img, rect, image.NewUniform(colors[i]), image.Point{}, draw.Src)
+	}
+}
+
+// fakeModel returns a model with a 4x4 input that runs on a fake engine.
+func fakeModel(preProcess *fakePreProcess, postProcess models.IPostProcess, batchSize int) *YOLO {
+	return &YOLO{
+		preProcessor:  preProcess,
+		engine:        &fakeEngine{input: make([]float32, batchSize*3*4*4)},
+		postProcessor: postProcess,
+		inputShape:    4,
+		batchSize:     batchSize,
+		defaults:      PredictOptions{ScoreThreshold: 0.25, NMSThreshold: 0.45},
+	}
+}
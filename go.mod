@@ -3,7 +3,7 @@ module github.com/zazamaza/yolo-object-detection-go
 go 1.23.1
 
 require (
-	github.com/disintegration/imaging v1.6.2 // indirect
-	github.com/yalue/onnxruntime_go v1.13.0 // indirect
-	golang.org/x/image v0.0.0-20191009234506-e7c1f5e7dbb8 // indirect
+	github.com/disintegration/imaging v1.6.2
+	github.com/yalue/onnxruntime_go v1.13.0
+	golang.org/x/image v0.0.0-20191009234506-e7c1f5e7dbb8
 )
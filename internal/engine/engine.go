@@ -2,7 +2,11 @@ package engine
 
 type IEngine interface {
 	SetInput(input *[]float32)
+	// InputData returns the input tensor buffer, which can be filled in
+	// place instead of calling SetInput.
+	InputData() []float32
 	GetOutput() []float32
+	GetOutputs() [][]float32
 	Run() error
 	Destroy()
 }
@@ -14,6 +18,22 @@ const (
 	CUDA
 	OpenVINO
 	TensorRT
-	DirectML 
+	DirectML
 	CoreML
 )
+
+// TensorType is the element type of a model output tensor.
+type TensorType int
+
+const (
+	Float32 TensorType = iota
+	Int32
+	Int64
+)
+
+// OutputSpec describes one output tensor of the model.
+type OutputSpec struct {
+	Name  string
+	Shape []int64
+	Type  TensorType
+}
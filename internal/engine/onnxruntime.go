@@ -10,9 +10,12 @@ import (
 type ONNXRuntime struct {
 	Session     *ort.AdvancedSession
 	Input       *ort.Tensor[float32]
-	Output      *ort.Tensor[float32]
+	Outputs     []ort.ArbitraryTensor
+	OutputSpecs []OutputSpec
 	InputShape  []int64
-	OutputShape []int64
+	// outputData holds the float32 view of every output; integer outputs
+	// are converted into it after each run.
+	outputData [][]float32
 }
 
 func getSharedLibPath() (string, error) {
@@ -80,20 +83,47 @@ func (e *ONNXRuntime) setupExecutionProvider(options *ort.SessionOptions, provid
 	}
 }
 
+func newOutputTensor(spec OutputSpec) (ort.ArbitraryTensor, error) {
+	shape := ort.NewShape(spec.Shape...)
+	switch spec.Type {
+	case Float32:
+		return ort.NewEmptyTensor[float32](shape)
+	case Int32:
+		return ort.NewEmptyTensor[int32](shape)
+	case Int64:
+		return ort.NewEmptyTensor[int64](shape)
+	default:
+		return nil, fmt.Errorf("unsupported tensor type: %v", spec.Type)
+	}
+}
+
+func destroyTensors(tensors []ort.ArbitraryTensor) {
+	for _, tensor := range tensors {
+		tensor.Destroy()
+	}
+}
+
 func NewEngine(modelPath string,
-	inputName, outputName string,
-	inputShape, outputShape []int64,
+	inputName string,
+	inputShape []int64,
+	outputs []OutputSpec,
 	provider ExecutionProvider,
 ) (*ONNXRuntime, error) {
 
+	if len(outputs) == 0 {
+		return nil, fmt.Errorf("at least one output is required")
+	}
+
 	libPath, err := getSharedLibPath()
 	if err != nil {
 		return nil, fmt.Errorf("error getting shared library path: %w", err)
 	}
 
 	ort.SetSharedLibraryPath(libPath)
-	if err := ort.InitializeEnvironment(); err != nil {
-		return nil, fmt.Errorf("error initializing ORT environment: %w", err)
+	if !ort.IsInitialized() {
+		if err := ort.InitializeEnvironment(); err != nil {
+			return nil, fmt.Errorf("error initializing ORT environment: %w", err)
+		}
 	}
 
 	ortInputShape := ort.NewShape(inputShape...)
@@ -102,42 +132,55 @@ func NewEngine(modelPath string,
 		return nil, fmt.Errorf("error creating input tensor: %w", err)
 	}
 
-	ortOutputShape := ort.NewShape(outputShape...)
-	outputTensor, err := ort.NewEmptyTensor[float32](ortOutputShape)
-	if err != nil {
-		inputTensor.Destroy()
-		return nil, fmt.Errorf("error creating output tensor: %w", err)
+	outputNames := make([]string, len(outputs))
+	outputTensors := make([]ort.ArbitraryTensor, 0, len(outputs))
+	outputData := make([][]float32, len(outputs))
+	for i, spec := range outputs {
+		outputTensor, err := newOutputTensor(spec)
+		if err != nil {
+			inputTensor.Destroy()
+			destroyTensors(outputTensors)
+			return nil, fmt.Errorf("error creating output tensor %s: %w", spec.Name, err)
+		}
+		outputNames[i] = spec.Name
+		outputTensors = append(outputTensors, outputTensor)
+		if floatTensor, ok := outputTensor.(*ort.Tensor[float32]); ok {
+			outputData[i] = floatTensor.GetData()
+		} else {
+			outputData[i] = make([]float32, ort.NewShape(spec.Shape...).FlattenedSize())
+		}
 	}
 
 	options, err := ort.NewSessionOptions()
 	if err != nil {
 		inputTensor.Destroy()
-		outputTensor.Destroy()
+		destroyTensors(outputTensors)
 		return nil, fmt.Errorf("error creating session options: %w", err)
 	}
 	defer options.Destroy()
 
 	engine := &ONNXRuntime{
 		Input:       inputTensor,
-		Output:      outputTensor,
+		Outputs:     outputTensors,
+		OutputSpecs: outputs,
 		InputShape:  inputShape,
-		OutputShape: outputShape,
+		outputData:  outputData,
 	}
 
 	if err := engine.setupExecutionProvider(options, provider); err != nil {
 		inputTensor.Destroy()
-		outputTensor.Destroy()
+		destroyTensors(outputTensors)
 		return nil, fmt.Errorf("error setting up execution provider: %w", err)
 	}
 
 	session, err := ort.NewAdvancedSession(modelPath,
-		[]string{inputName}, []string{outputName},
+		[]string{inputName}, outputNames,
 		[]ort.ArbitraryTensor{inputTensor},
-		[]ort.ArbitraryTensor{outputTensor},
+		outputTensors,
 		options)
 	if err != nil {
 		inputTensor.Destroy()
-		outputTensor.Destroy()
+		destroyTensors(outputTensors)
 		return nil, fmt.Errorf("error creating session: %w", err)
 	}
 
@@ -148,21 +191,49 @@ func NewEngine(modelPath string,
 func (e *ONNXRuntime) SetInput(input *[]float32) {
 	data := e.Input.GetData()
 	inputData := *input
-	for i := 0; i < len(data); i++ {
-		data[i] = inputData[i]
+	// Input written through InputData is already in place.
+	if len(inputData) > 0 && &inputData[0] == &data[0] {
+		return
 	}
+	copy(data, inputData)
+}
+
+func (e *ONNXRuntime) InputData() []float32 {
+	return e.Input.GetData()
 }
 
 func (e *ONNXRuntime) Run() error {
-	return e.Session.Run()
+	if err := e.Session.Run(); err != nil {
+		return err
+	}
+
+	for i, output := range e.Outputs {
+		switch tensor := output.(type) {
+		case *ort.Tensor[int32]:
+			data := e.outputData[i]
+			for j, value := range tensor.GetData() {
+				data[j] = float32(value)
+			}
+		case *ort.Tensor[int64]:
+			data := e.outputData[i]
+			for j, value := range tensor.GetData() {
+				data[j] = float32(value)
+			}
+		}
+	}
+	return nil
 }
 
 func (e *ONNXRuntime) GetOutput() []float32 {
-	return e.Output.GetData()
+	return e.outputData[0]
+}
+
+func (e *ONNXRuntime) GetOutputs() [][]float32 {
+	return e.outputData
 }
 
 func (e *ONNXRuntime) Destroy() {
 	e.Session.Destroy()
 	e.Input.Destroy()
-	e.Output.Destroy()
+	destroyTensors(e.Outputs)
 }
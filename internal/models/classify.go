@@ -0,0 +1,98 @@
+package model
+
+import (
+	"math"
+	"slices"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// ClassifierConfiguration describes an image classification model with a
+// single [batch, classes] output, such as a YOLO -cls export.
+type ClassifierConfiguration struct {
+	ModelPath  string
+	InputName  string
+	OutputName string
+	InputShape []int64
+	Classes    []string
+	// Softmax is set for models that output logits instead of
+	// probabilities.
+	Softmax bool
+	// Letterbox controls how crops are resized to the input.
+	Letterbox     utils.LetterboxOptions
+	Normalization Normalization
+}
+
+func NewClassifierConfiguration() ClassifierConfiguration {
+	return ClassifierConfiguration{
+		ModelPath:  "classifier.onnx",
+		InputName:  "images",
+		OutputName: "output0",
+		InputShape: []int64{1, 3, 224, 224},
+	}
+}
+
+// OutputShape returns the shape of the class scores output.
+func (c *ClassifierConfiguration) OutputShape() []int64 {
+	return []int64{c.InputShape[0], int64(len(c.Classes))}
+}
+
+type ClassifyPostProcess struct {
+	Classes []string
+	Softmax bool
+}
+
+// PostProcess returns the topK classes of one item of the output, best
+// first. A topK of zero returns every class.
+func (c *ClassifyPostProcess) PostProcess(output []float32, topK int) []utils.Classification {
+	scores := output[:min(len(output), len(c.Classes))]
+	if c.Softmax {
+		scores = softmax(scores)
+	}
+
+	indices := make([]int, len(scores))
+	for i := range indices {
+		indices[i] = i
+	}
+	slices.SortStableFunc(indices, func(a, b int) int {
+		switch {
+		case scores[a] > scores[b]:
+			return -1
+		case scores[a] < scores[b]:
+			return 1
+		}
+		return 0
+	})
+	if topK > 0 && topK < len(indices) {
+		indices = indices[:topK]
+	}
+
+	results := make([]utils.Classification, len(indices))
+	for i, index := range indices {
+		results[i] = utils.Classification{
+			Label:      c.Classes[index],
+			ClassID:    index,
+			Confidence: scores[index],
+		}
+	}
+	return results
+}
+
+func softmax(logits []float32) []float32 {
+	probabilities := make([]float32, len(logits))
+	if len(logits) == 0 {
+		return probabilities
+	}
+
+	highest := slices.Max(logits)
+	var sum float64
+	for i, logit := range logits {
+		exp := math.Exp(float64(logit - highest))
+		probabilities[i] = float32(exp)
+		sum += exp
+	}
+	for i := range probabilities {
+		probabilities[i] = float32(float64(probabilities[i]) / sum)
+	}
+	return probabilities
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+func TestClassifyPostProcess(t *testing.T) {
+	postProcess := &ClassifyPostProcess{Classes: []string{"sedan", "suv", "truck", "van"}}
+
+	result := postProcess.PostProcess([]float32{0.1, 0.6, 0.05, 0.25}, 2)
+	expected := []utils.Classification{
+		{Label: "suv", ClassID: 1, Confidence: 0.6},
+		{Label: "van", ClassID: 3, Confidence: 0.25},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+
+	if all := postProcess.PostProcess([]float32{0.1, 0.6, 0.05, 0.25}, 0); len(all) != 4 {
+		t.Errorf("expected every class for topK 0, got %d", len(all))
+	}
+}
+
+func TestClassifyPostProcessSoftmax(t *testing.T) {
+	postProcess := &ClassifyPostProcess{Classes: []string{"a", "b"}, Softmax: true}
+
+	result := postProcess.PostProcess([]float32{0, float32(math.Log(3))}, 0)
+	if result[0].Label != "b" || math.Abs(float64(result[0].Confidence)-0.75) > 1e-6 {
+		t.Errorf("expected b with 0.75, got %+v", result[0])
+	}
+	if math.Abs(float64(result[1].Confidence)-0.25) > 1e-6 {
+		t.Errorf("expected a with 0.25, got %+v", result[1])
+	}
+}
+
+func TestClassifierConfigurationOutputShape(t *testing.T) {
+	configuration := NewClassifierConfiguration()
+	configuration.InputShape = []int64{8, 3, 224, 224}
+	configuration.Classes = []string{"a", "b", "c"}
+
+	expected := []int64{8, 3}
+	if shape := configuration.OutputShape(); !reflect.DeepEqual(shape, expected) {
+		t.Errorf("expected %v, got %v", expected, shape)
+	}
+}
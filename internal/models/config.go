@@ -1,5 +1,12 @@
 package model
 
+import (
+	"fmt"
+
+	engine "github.com/zazamaza/yolo-object-detection-go/internal/engine"
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
 type YOLOVersion int
 
 const (
@@ -9,6 +16,56 @@ const (
 	YOLOv11
 )
 
+func (v YOLOVersion) String() string {
+	switch v {
+	case YOLOv5:
+		return "yolov5"
+	case YOLOv8:
+		return "yolov8"
+	case YOLOv10:
+		return "yolov10"
+	case YOLOv11:
+		return "yolo11"
+	}
+	return fmt.Sprintf("YOLOVersion(%d)", int(v))
+}
+
+// NMSLayout is the output layout of a model with NMS baked into the graph.
+type NMSLayout int
+
+const (
+	// NMSLayoutPacked is a single [1, N, 6] tensor of x1, y1, x2, y2, score,
+	// class rows (Ultralytics nms=True, YOLOv10).
+	NMSLayoutPacked NMSLayout = iota
+	// NMSLayoutSplit is the EfficientNMS layout with separate num_dets,
+	// det_boxes, det_scores and det_classes outputs.
+	NMSLayoutSplit
+)
+
+// BoxFormat is the coordinate format of boxes emitted by the model.
+type BoxFormat int
+
+const (
+	BoxXYXY BoxFormat = iota
+	BoxXYWH
+)
+
+// EndToEndConfiguration describes the outputs of a model that already
+// performs NMS, so no suppression is done on the Go side.
+type EndToEndConfiguration struct {
+	Layout    NMSLayout
+	BoxFormat BoxFormat
+	// Output names used by NMSLayoutSplit; NMSLayoutPacked reads
+	// YOLOConfiguration.OutputName instead.
+	NumDetsName string
+	BoxesName   string
+	ScoresName  string
+	ClassesName string
+	// IndexType is the element type of the num_dets and det_classes outputs.
+	IndexType     engine.TensorType
+	MaxDetections int
+}
+
 type YOLOConfiguration struct {
 	ModelPath   string
 	InputName   string
@@ -17,6 +74,21 @@ type YOLOConfiguration struct {
 	OutputShape []int64
 	Classes     []string
 	Version     YOLOVersion
+	// Prediction defaults, overridable per Predict call.
+	ScoreThreshold float32
+	NMSThreshold   float32
+	// AgnosticNMS lets boxes of different classes suppress each other.
+	AgnosticNMS bool
+	// MaxDetections caps the boxes kept after NMS; zero means no cap.
+	MaxDetections int
+	// Suppressor replaces the default greedy NMS, e.g. with Soft-NMS or WBF.
+	Suppressor utils.ISuppressor
+	// EndToEnd is set for exports with NMS in the graph.
+	EndToEnd *EndToEndConfiguration
+	// Letterbox controls how images are resized and padded to the input.
+	Letterbox utils.LetterboxOptions
+	// Normalization controls how pixels are turned into input values.
+	Normalization Normalization
 }
 
 func NewYOLOConfiguration() YOLOConfiguration {
@@ -30,6 +102,40 @@ func NewYOLOConfiguration() YOLOConfiguration {
 		"Head", "Enemy", "Flashed",
 	}
 	configuration.Version = YOLOv11
+	configuration.ScoreThreshold = 0.25
+	configuration.NMSThreshold = 0.45
+	configuration.MaxDetections = 300
 
 	return configuration
 }
+
+func NewEndToEndConfiguration(layout NMSLayout) EndToEndConfiguration {
+	return EndToEndConfiguration{
+		Layout:        layout,
+		BoxFormat:     BoxXYXY,
+		NumDetsName:   "num_dets",
+		BoxesName:     "det_boxes",
+		ScoresName:    "det_scores",
+		ClassesName:   "det_classes",
+		IndexType:     engine.Int32,
+		MaxDetections: 100,
+	}
+}
+
+// Outputs returns the engine outputs the model is expected to produce.
+func (c *YOLOConfiguration) Outputs() []engine.OutputSpec {
+	if c.EndToEnd == nil || c.EndToEnd.Layout == NMSLayoutPacked {
+		return []engine.OutputSpec{
+			{Name: c.OutputName, Shape: c.OutputShape, Type: engine.Float32},
+		}
+	}
+
+	batch := c.InputShape[0]
+	maxDetections := int64(c.EndToEnd.MaxDetections)
+	return []engine.OutputSpec{
+		{Name: c.EndToEnd.NumDetsName, Shape: []int64{batch, 1}, Type: c.EndToEnd.IndexType},
+		{Name: c.EndToEnd.BoxesName, Shape: []int64{batch, maxDetections, 4}, Type: engine.Float32},
+		{Name: c.EndToEnd.ScoresName, Shape: []int64{batch, maxDetections}, Type: engine.Float32},
+		{Name: c.EndToEnd.ClassesName, Shape: []int64{batch, maxDetections}, Type: c.EndToEnd.IndexType},
+	}
+}
@@ -47,4 +47,36 @@ func TestNewYOLOConfiguration(t *testing.T) {
 	if config.Version != expectedConfig.Version {
 		t.Errorf("Version mismatch. Expected %v, got %v", expectedConfig.Version, config.Version)
 	}
+
+	if config.ScoreThreshold != 0.25 || config.NMSThreshold != 0.45 || config.MaxDetections != 300 {
+		t.Errorf("Prediction defaults mismatch. Got score %v, NMS %v, max detections %v",
+			config.ScoreThreshold, config.NMSThreshold, config.MaxDetections)
+	}
+}
+
+func TestYOLOConfigurationOutputs(t *testing.T) {
+	config := NewYOLOConfiguration()
+
+	outputs := config.Outputs()
+	if len(outputs) != 1 || outputs[0].Name != "output0" {
+		t.Fatalf("Expected single output0, got %+v", outputs)
+	}
+
+	endToEnd := NewEndToEndConfiguration(NMSLayoutSplit)
+	endToEnd.MaxDetections = 50
+	config.EndToEnd = &endToEnd
+
+	outputs = config.Outputs()
+	expectedNames := []string{"num_dets", "det_boxes", "det_scores", "det_classes"}
+	if len(outputs) != len(expectedNames) {
+		t.Fatalf("Expected %d outputs, got %d", len(expectedNames), len(outputs))
+	}
+	for i, name := range expectedNames {
+		if outputs[i].Name != name {
+			t.Errorf("Output %d name mismatch. Expected %s, got %s", i, name, outputs[i].Name)
+		}
+	}
+	if !reflect.DeepEqual(outputs[1].Shape, []int64{1, 50, 4}) {
+		t.Errorf("det_boxes shape mismatch. Expected [1 50 4], got %v", outputs[1].Shape)
+	}
 }
@@ -7,7 +7,7 @@ import (
 )
 
 type IPreProcess interface {
-	PreProcess(img image.Image, dst *[]float32) (float32, int, int)
+	PreProcess(img image.Image, dst *[]float32) utils.Transform
 }
 
 type IModel interface {
@@ -15,9 +15,9 @@ type IModel interface {
 }
 
 type IPostProcess interface {
-	PostProcess(output []float32,
+	PostProcess(outputs [][]float32,
 		originalWidth, originalHeight int,
-		scoreThreshold, nmsThreshold, scale float32,
-		dw, dh int,
+		transform utils.Transform,
+		options *PostProcessOptions,
 	) []utils.BoundingBox
 }
@@ -0,0 +1,166 @@
+package model
+
+import (
+	"math"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// PostProcessOptions controls how raw model output is turned into
+// detections. Classes are referred to by label.
+type PostProcessOptions struct {
+	ScoreThreshold float32
+	NMSThreshold   float32
+	// ClassThresholds overrides ScoreThreshold for individual classes.
+	ClassThresholds map[string]float32
+	// IncludeClasses keeps only these classes when not empty.
+	IncludeClasses []string
+	// ExcludeClasses drops these classes.
+	ExcludeClasses []string
+	// MaxPerClass caps the detections kept for each class; zero means no cap.
+	MaxPerClass int
+	// MaxDetections caps the detections kept in total; zero means no cap.
+	MaxDetections int
+	// Agnostic lets boxes of different classes suppress each other.
+	Agnostic bool
+	// SkipNMS returns every candidate above its threshold.
+	SkipNMS bool
+	// ExclusionMasks drop candidates before suppression and the caps, so
+	// they neither suppress valid boxes nor use up detection slots.
+	ExclusionMasks []utils.ExclusionMask
+	// Placement maps a decoded box into the coordinates of the masks, such
+	// as from a slice or ROI into the full frame; nil means they match.
+	Placement func(*utils.BoundingBox)
+}
+
+// thresholds resolves the options into one score threshold per class
+// index. Filtered out classes get an infinite threshold so the decode loop
+// drops them before they reach NMS.
+func (o *PostProcessOptions) thresholds(classes []string) []float32 {
+	thresholds := make([]float32, len(classes))
+	for i := range thresholds {
+		thresholds[i] = o.ScoreThreshold
+	}
+
+	for i, label := range classes {
+		if threshold, ok := o.ClassThresholds[label]; ok {
+			thresholds[i] = threshold
+		}
+	}
+
+	if len(o.IncludeClasses) > 0 {
+		included := make(map[string]bool, len(o.IncludeClasses))
+		for _, label := range o.IncludeClasses {
+			included[label] = true
+		}
+		for i, label := range classes {
+			if !included[label] {
+				thresholds[i] = float32(math.Inf(1))
+			}
+		}
+	}
+
+	for _, label := range o.ExcludeClasses {
+		for i := range classes {
+			if classes[i] == label {
+				thresholds[i] = float32(math.Inf(1))
+			}
+		}
+	}
+	return thresholds
+}
+
+// minThreshold is the lowest score any class can pass with, used to
+// prefilter candidates before NMS.
+func minThreshold(thresholds []float32) float32 {
+	lowest := float32(math.Inf(1))
+	for _, threshold := range thresholds {
+		lowest = min(lowest, threshold)
+	}
+	return lowest
+}
+
+// filterExcluded drops the candidates excluded by the masks, testing each
+// box where Placement puts it. It reuses the backing array of boxes.
+func (o *PostProcessOptions) filterExcluded(boxes []utils.BoundingBox) []utils.BoundingBox {
+	if len(o.ExclusionMasks) == 0 {
+		return boxes
+	}
+
+	results := boxes[:0]
+	for _, box := range boxes {
+		placed := box
+		if o.Placement != nil {
+			o.Placement(&placed)
+		}
+		if !utils.Excluded(&placed, o.ExclusionMasks) {
+			results = append(results, box)
+		}
+	}
+	return results
+}
+
+// LimitDetections applies the per-class and total detection caps.
+func LimitDetections(boxes []utils.BoundingBox, options *PostProcessOptions) []utils.BoundingBox {
+	boxes = limitPerClass(boxes, options.MaxPerClass)
+	if options.MaxDetections > 0 && len(boxes) > options.MaxDetections {
+		boxes = boxes[:options.MaxDetections]
+	}
+	return boxes
+}
+
+// limitPerClass keeps at most maxPerClass boxes of each class, preserving
+// the order of boxes.
+func limitPerClass(boxes []utils.BoundingBox, maxPerClass int) []utils.BoundingBox {
+	if maxPerClass <= 0 {
+		return boxes
+	}
+
+	counts := map[int]int{}
+	results := boxes[:0]
+	for _, box := range boxes {
+		if counts[box.ClassID] < maxPerClass {
+			counts[box.ClassID]++
+			results = append(results, box)
+		}
+	}
+	return results
+}
+
+// FilterBoxes applies the class filters and score thresholds by label to
+// already decoded boxes, e.g. after relabelling them. It reuses the
+// backing array of boxes.
+func FilterBoxes(boxes []utils.BoundingBox, options *PostProcessOptions) []utils.BoundingBox {
+	excluded := make(map[string]bool, len(options.ExcludeClasses))
+	for _, label := range options.ExcludeClasses {
+		excluded[label] = true
+	}
+	included := make(map[string]bool, len(options.IncludeClasses))
+	for _, label := range options.IncludeClasses {
+		included[label] = true
+	}
+
+	results := boxes[:0]
+	for _, box := range boxes {
+		if excluded[box.Label] || (len(included) > 0 && !included[box.Label]) {
+			continue
+		}
+		threshold, ok := options.ClassThresholds[box.Label]
+		if !ok {
+			threshold = options.ScoreThreshold
+		}
+		if box.Confidence >= threshold {
+			results = append(results, box)
+		}
+	}
+	return results
+}
+
+// LowestThreshold returns the lowest score threshold of any class.
+func (o *PostProcessOptions) LowestThreshold() float32 {
+	lowest := o.ScoreThreshold
+	for _, threshold := range o.ClassThresholds {
+		lowest = min(lowest, threshold)
+	}
+	return lowest
+}
@@ -0,0 +1,131 @@
+package model
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+func TestPostProcessOptionsThresholds(t *testing.T) {
+	classes := []string{"person", "car", "dog", "sign"}
+	inf := float32(math.Inf(1))
+
+	tests := []struct {
+		name     string
+		options  PostProcessOptions
+		expected []float32
+	}{
+		{
+			name:     "Global threshold",
+			options:  PostProcessOptions{ScoreThreshold: 0.5},
+			expected: []float32{0.5, 0.5, 0.5, 0.5},
+		},
+		{
+			name: "Per-class override",
+			options: PostProcessOptions{
+				ScoreThreshold:  0.5,
+				ClassThresholds: map[string]float32{"dog": 0.2, "unknown": 0.1},
+			},
+			expected: []float32{0.5, 0.5, 0.2, 0.5},
+		},
+		{
+			name: "Include and exclude",
+			options: PostProcessOptions{
+				ScoreThreshold: 0.5,
+				IncludeClasses: []string{"person", "car", "sign"},
+				ExcludeClasses: []string{"sign"},
+			},
+			expected: []float32{0.5, 0.5, inf, inf},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.options.thresholds(classes)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestLimitPerClass(t *testing.T) {
+	boxes := []utils.BoundingBox{
+		{Label: "a", ClassID: 0, Confidence: 0.9},
+		{Label: "b", ClassID: 1, Confidence: 0.8},
+		{Label: "c", ClassID: 0, Confidence: 0.7},
+		{Label: "d", ClassID: 0, Confidence: 0.6},
+		{Label: "e", ClassID: 1, Confidence: 0.5},
+	}
+
+	result := limitPerClass(boxes, 1)
+	labels := []string{}
+	for _, box := range result {
+		labels = append(labels, box.Label)
+	}
+	if !reflect.DeepEqual(labels, []string{"a", "b"}) {
+		t.Errorf("expected [a b], got %v", labels)
+	}
+}
+
+func TestPostProcessClassFilters(t *testing.T) {
+	yolo := YOLOPostProcess{
+		OutputShape: 3,
+		ImageUtils:  &utils.ImageUtils{},
+		Classes:     []string{"person", "car", "sign"},
+	}
+
+	// Three separate boxes: a weak car, a person and a sign.
+	output := []float32{
+		50, 250, 450, // xc
+		50, 250, 450, // yc
+		40, 40, 40, // w
+		40, 40, 40, // h
+		0.0, 0.9, 0.0, // person
+		0.3, 0.0, 0.0, // car
+		0.0, 0.0, 0.8, // sign
+	}
+
+	options := &PostProcessOptions{
+		ScoreThreshold:  0.5,
+		NMSThreshold:    0.5,
+		ClassThresholds: map[string]float32{"car": 0.25},
+		ExcludeClasses:  []string{"sign"},
+	}
+
+	result := yolo.PostProcess([][]float32{output}, 640, 640, utils.Transform{ScaleX: 1.0, ScaleY: 1.0, PadX: 0, PadY: 0}, options)
+	if len(result) != 2 || result[0].Label != "person" || result[1].Label != "car" {
+		t.Errorf("expected person and car, got %+v", result)
+	}
+}
+
+func TestFilterBoxes(t *testing.T) {
+	boxes := []utils.BoundingBox{
+		{Label: "person", Confidence: 0.6},
+		{Label: "person", Confidence: 0.4},
+		{Label: "dog", Confidence: 0.3},
+		{Label: "sign", Confidence: 0.9},
+		{Label: "cat", Confidence: 0.9},
+	}
+	options := &PostProcessOptions{
+		ScoreThreshold:  0.5,
+		ClassThresholds: map[string]float32{"dog": 0.25},
+		IncludeClasses:  []string{"person", "dog", "sign"},
+		ExcludeClasses:  []string{"sign"},
+	}
+
+	if lowest := options.LowestThreshold(); lowest != 0.25 {
+		t.Errorf("expected lowest threshold 0.25, got %f", lowest)
+	}
+
+	result := FilterBoxes(boxes, options)
+	expected := []utils.BoundingBox{
+		{Label: "person", Confidence: 0.6},
+		{Label: "dog", Confidence: 0.3},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+}
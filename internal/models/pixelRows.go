@@ -0,0 +1,197 @@
+package model
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// rowReader reads rows of a source image as 8-bit straight-alpha RGBA,
+// converting them the way imaging does so the fused pass sees the same
+// pixels as Letterbox.
+type rowReader struct {
+	img   image.Image
+	width int
+	// composite blends translucent pixels over background.
+	composite  bool
+	background [3]uint8
+
+	// palette caches the converted colours of paletteOf.
+	palette   [256][4]uint8
+	paletteOf color.Palette
+}
+
+// reset points the reader at img and reports whether its type is supported.
+func (r *rowReader) reset(img image.Image) bool {
+	r.img = img
+	r.width = img.Bounds().Dx()
+
+	switch typedImg := img.(type) {
+	case *image.NRGBA, *image.RGBA, *image.NRGBA64, *image.RGBA64, *image.Gray, *image.Gray16, *utils.RawImage:
+		return true
+	case *image.YCbCr:
+		// Chroma offsets are computed with shifts, which only match
+		// division for non-negative coordinates.
+		return typedImg.Rect.Min.X >= 0 && typedImg.Rect.Min.Y >= 0 &&
+			typedImg.SubsampleRatio >= image.YCbCrSubsampleRatio444 &&
+			typedImg.SubsampleRatio <= image.YCbCrSubsampleRatio410
+	case *image.Paletted:
+		if len(typedImg.Palette) > len(r.palette) {
+			return false
+		}
+		r.cachePalette(typedImg.Palette)
+		return true
+	}
+	return false
+}
+
+// cachePalette converts palette unless it is the one already cached.
+func (r *rowReader) cachePalette(palette color.Palette) {
+	if len(palette) == len(r.paletteOf) && (len(palette) == 0 || &palette[0] == &r.paletteOf[0]) {
+		return
+	}
+	for i, c := range palette {
+		nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+		r.palette[i] = [4]uint8{nrgba.R, nrgba.G, nrgba.B, nrgba.A}
+	}
+	r.paletteOf = palette
+}
+
+// row returns row y, counted from the top of the image. NRGBA rows are
+// returned in place unless they are composited; other types are converted
+// into buf, which must hold four bytes per pixel.
+func (r *rowReader) row(y int, buf []uint8) []uint8 {
+	row := r.convert(y, buf)
+	if !r.composite {
+		return row
+	}
+	if &row[0] != &buf[0] {
+		copy(buf, row[:r.width*4])
+	}
+	utils.Composite(buf[:r.width*4], r.background)
+	return buf
+}
+
+// convert returns row y as straight-alpha RGBA.
+func (r *rowReader) convert(y int, buf []uint8) []uint8 {
+	switch img := r.img.(type) {
+	case *image.NRGBA:
+		return img.Pix[img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y+y):]
+
+	case *image.RGBA:
+		src := img.Pix[img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y+y):]
+		for x := 0; x < r.width; x++ {
+			s, d := src[x*4:x*4+4:x*4+4], buf[x*4:x*4+4:x*4+4]
+			switch a := s[3]; a {
+			case 0:
+				d[0], d[1], d[2], d[3] = 0, 0, 0, 0
+			case 0xff:
+				d[0], d[1], d[2], d[3] = s[0], s[1], s[2], a
+			default:
+				a16 := uint16(a)
+				d[0] = uint8(uint16(s[0]) * 0xff / a16)
+				d[1] = uint8(uint16(s[1]) * 0xff / a16)
+				d[2] = uint8(uint16(s[2]) * 0xff / a16)
+				d[3] = a
+			}
+		}
+
+	case *image.NRGBA64:
+		src := img.Pix[img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y+y):]
+		for x := 0; x < r.width; x++ {
+			s, d := src[x*8:x*8+8:x*8+8], buf[x*4:x*4+4:x*4+4]
+			d[0], d[1], d[2], d[3] = s[0], s[2], s[4], s[6]
+		}
+
+	case *image.RGBA64:
+		src := img.Pix[img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y+y):]
+		for x := 0; x < r.width; x++ {
+			s, d := src[x*8:x*8+8:x*8+8], buf[x*4:x*4+4:x*4+4]
+			switch a := s[6]; a {
+			case 0:
+				d[0], d[1], d[2] = 0, 0, 0
+			case 0xff:
+				d[0], d[1], d[2] = s[0], s[2], s[4]
+			default:
+				a32 := uint32(s[6])<<8 | uint32(s[7])
+				d[0] = uint8((uint32(s[0])<<8 | uint32(s[1])) * 0xffff / a32 >> 8)
+				d[1] = uint8((uint32(s[2])<<8 | uint32(s[3])) * 0xffff / a32 >> 8)
+				d[2] = uint8((uint32(s[4])<<8 | uint32(s[5])) * 0xffff / a32 >> 8)
+			}
+			d[3] = s[6]
+		}
+
+	case *image.Gray:
+		src := img.Pix[img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y+y):]
+		for x := 0; x < r.width; x++ {
+			c, d := src[x], buf[x*4:x*4+4:x*4+4]
+			d[0], d[1], d[2], d[3] = c, c, c, 0xff
+		}
+
+	case *image.Gray16:
+		src := img.Pix[img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y+y):]
+		for x := 0; x < r.width; x++ {
+			c, d := src[x*2], buf[x*4:x*4+4:x*4+4]
+			d[0], d[1], d[2], d[3] = c, c, c, 0xff
+		}
+
+	case *image.YCbCr:
+		r.ycbcrRow(img, y, buf)
+
+	case *utils.RawImage:
+		return img.RowRGBA(y, buf)
+
+	case *image.Paletted:
+		src := img.Pix[img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y+y):]
+		for x := 0; x < r.width; x++ {
+			copy(buf[x*4:x*4+4], r.palette[src[x]][:])
+		}
+	}
+	return buf
+}
+
+// ycbcrRow converts row y of img, reading the chroma samples shared by
+// neighbouring pixels under every subsample ratio.
+func (r *rowReader) ycbcrRow(img *image.YCbCr, y int, buf []uint8) {
+	var xShift, yShift uint
+	switch img.SubsampleRatio {
+	case image.YCbCrSubsampleRatio422:
+		xShift = 1
+	case image.YCbCrSubsampleRatio420:
+		xShift, yShift = 1, 1
+	case image.YCbCrSubsampleRatio440:
+		yShift = 1
+	case image.YCbCrSubsampleRatio411:
+		xShift = 2
+	case image.YCbCrSubsampleRatio410:
+		xShift, yShift = 2, 1
+	}
+
+	minX, minY := img.Rect.Min.X, img.Rect.Min.Y
+	luma := img.Y[img.YOffset(minX, minY+y):][:r.width]
+	chroma := ((minY+y)>>yShift - minY>>yShift) * img.CStride
+	cb, cr := img.Cb[chroma:], img.Cr[chroma:]
+	for x, yy := range luma {
+		ci := (minX+x)>>xShift - minX>>xShift
+		d := buf[x*4 : x*4+4 : x*4+4]
+		d[0], d[1], d[2] = ycbcrToRGB(yy, cb[ci], cr[ci])
+		d[3] = 0xff
+	}
+}
+
+// ycbcrToRGB is color.YCbCrToRGB, small enough to be inlined.
+func ycbcrToRGB(y, cb, cr uint8) (uint8, uint8, uint8) {
+	yy := int32(y) * 0x10101
+	cb1 := int32(cb) - 128
+	cr1 := int32(cr) - 128
+	return clamp16(yy + 91881*cr1), clamp16(yy - 22554*cb1 - 46802*cr1), clamp16(yy + 116130*cb1)
+}
+
+// clamp16 clamps a 16.16 fixed-point value to a byte.
+func clamp16(v int32) uint8 {
+	if uint32(v)&0xff000000 == 0 {
+		return uint8(v >> 16)
+	}
+	return uint8(^(v >> 31))
+}
@@ -1,8 +1,8 @@
 package model
 
 import (
-	"image"
 	"math"
+	"slices"
 
 	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
 )
@@ -11,13 +11,22 @@ type YOLOPostProcess struct {
 	OutputShape int
 	ImageUtils  utils.IImageUtils
 	Classes     []string
+	// Suppressor replaces the default greedy NMS when set.
+	Suppressor utils.ISuppressor
+
+	// Buffers reused between calls.
+	boxes    []utils.Box
+	scores   []float32
+	classIDs []int
 }
 
-func (yo *YOLOPostProcess) PostProcess(output []float32,
+func (yo *YOLOPostProcess) PostProcess(outputs [][]float32,
 	originalWidth, originalHeight int,
-	scoreThreshold, nmsThreshold, scale float32,
-	dw, dh int,
+	transform utils.Transform,
+	options *PostProcessOptions,
 ) []utils.BoundingBox {
+	output := outputs[0]
+	thresholds := options.thresholds(yo.Classes)
 
 	boundingBoxes := make([]utils.BoundingBox, 0, yo.OutputShape)
 
@@ -35,17 +44,15 @@ func (yo *YOLOPostProcess) PostProcess(output []float32,
 			}
 		}
 
-		if probability < scoreThreshold {
+		if probability < thresholds[classID] {
 			continue
 		}
 
 		xc, yc := output[index], output[yo.OutputShape+index]
 		w, h := output[2*yo.OutputShape+index], output[3*yo.OutputShape+index]
 
-		x1 := (xc - w/2 - float32(dw)) / scale
-		y1 := (yc - h/2 - float32(dh)) / scale
-		x2 := (xc + w/2 - float32(dw)) / scale
-		y2 := (yc + h/2 - float32(dh)) / scale
+		x1, y1 := transform.Restore(xc-w/2, yc-h/2)
+		x2, y2 := transform.Restore(xc+w/2, yc+h/2)
 
 		x1 = float32(math.Max(0, math.Min(float64(x1), float64(originalWidth))))
 		y1 = float32(math.Max(0, math.Min(float64(y1), float64(originalHeight))))
@@ -54,6 +61,7 @@ func (yo *YOLOPostProcess) PostProcess(output []float32,
 
 		boundingBoxes = append(boundingBoxes, utils.BoundingBox{
 			Label:      yo.Classes[classID],
+			ClassID:    classID,
 			Confidence: probability,
 			X1:         x1,
 			Y1:         y1,
@@ -62,17 +70,53 @@ func (yo *YOLOPostProcess) PostProcess(output []float32,
 		})
 	}
 
-	boxes := make([]image.Rectangle, len(boundingBoxes))
-	scores := make([]float32, len(boundingBoxes))
-	for i, b := range boundingBoxes {
-		boxes[i] = image.Rect(int(b.X1), int(b.Y1), int(b.X2), int(b.Y2))
-		scores[i] = b.Confidence
+	boundingBoxes = options.filterExcluded(boundingBoxes)
+	if options.SkipNMS {
+		return boundingBoxes
+	}
+
+	// The total cap is applied after the per-class one.
+	maxDetections := options.MaxDetections
+	if options.MaxPerClass > 0 {
+		maxDetections = 0
+	}
+
+	var results []utils.BoundingBox
+	if yo.Suppressor != nil {
+		results = utils.SuppressBatched(yo.Suppressor, boundingBoxes, options.NMSThreshold, options.Agnostic)
+		// Soft-NMS and Matrix-NMS decay scores instead of dropping boxes, so
+		// the class thresholds are applied again to the decayed scores.
+		results = slices.DeleteFunc(results, func(b utils.BoundingBox) bool {
+			return b.Confidence < thresholds[b.ClassID]
+		})
+	} else {
+		results = yo.nms(boundingBoxes, minThreshold(thresholds), options.NMSThreshold, options.Agnostic, maxDetections)
+	}
+
+	return LimitDetections(results, options)
+}
+
+func (yo *YOLOPostProcess) nms(boundingBoxes []utils.BoundingBox,
+	scoreThreshold, nmsThreshold float32,
+	agnostic bool, maxDetections int,
+) []utils.BoundingBox {
+
+	yo.boxes = yo.boxes[:0]
+	yo.scores = yo.scores[:0]
+	yo.classIDs = yo.classIDs[:0]
+	for _, b := range boundingBoxes {
+		yo.boxes = append(yo.boxes, utils.Box{X1: b.X1, Y1: b.Y1, X2: b.X2, Y2: b.Y2})
+		yo.scores = append(yo.scores, b.Confidence)
+		yo.classIDs = append(yo.classIDs, b.ClassID)
 	}
 
-	indices := yo.ImageUtils.NMSBoxes(&boxes,
-		&scores,
+	indices := yo.ImageUtils.NMSBoxesBatched(&yo.boxes,
+		&yo.scores,
+		&yo.classIDs,
 		scoreThreshold,
 		nmsThreshold,
+		agnostic,
+		maxDetections,
 	)
 
 	results := make([]utils.BoundingBox, len(*indices))
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"math"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// EndToEndPostProcess decodes the outputs of models that already ran NMS
+// in the graph. Detections are only thresholded and rescaled.
+type EndToEndPostProcess struct {
+	Layout        NMSLayout
+	BoxFormat     BoxFormat
+	MaxDetections int
+	Classes       []string
+}
+
+func (yo *EndToEndPostProcess) PostProcess(outputs [][]float32,
+	originalWidth, originalHeight int,
+	transform utils.Transform,
+	options *PostProcessOptions,
+) []utils.BoundingBox {
+
+	var count int
+	var box func(index int) (float32, float32, float32, float32, float32, int)
+
+	switch yo.Layout {
+	case NMSLayoutSplit:
+		numDets, boxes, scores, classes := outputs[0], outputs[1], outputs[2], outputs[3]
+		// A corrupt num_dets must not index past the detection buffers.
+		count = max(min(int(numDets[0]), len(scores), len(classes), len(boxes)/4), 0)
+		box = func(index int) (float32, float32, float32, float32, float32, int) {
+			return boxes[index*4], boxes[index*4+1], boxes[index*4+2], boxes[index*4+3],
+				scores[index], int(classes[index])
+		}
+	default:
+		output := outputs[0]
+		count = len(output) / 6
+		box = func(index int) (float32, float32, float32, float32, float32, int) {
+			return output[index*6], output[index*6+1], output[index*6+2], output[index*6+3],
+				output[index*6+4], int(output[index*6+5])
+		}
+	}
+
+	if yo.MaxDetections > 0 && count > yo.MaxDetections {
+		count = yo.MaxDetections
+	}
+
+	thresholds := options.thresholds(yo.Classes)
+	results := make([]utils.BoundingBox, 0, count)
+	for index := 0; index < count; index++ {
+		a, b, c, d, probability, classID := box(index)
+		if classID < 0 || classID >= len(yo.Classes) {
+			continue
+		}
+		if probability < thresholds[classID] {
+			continue
+		}
+
+		var xc, yc, w, h float32
+		if yo.BoxFormat == BoxXYWH {
+			xc, yc, w, h = a, b, c, d
+		} else {
+			xc, yc = (a+c)/2.0, (b+d)/2.0
+			w, h = c-a, d-b
+		}
+
+		x1, y1 := transform.Restore(xc-w/2, yc-h/2)
+		x2, y2 := transform.Restore(xc+w/2, yc+h/2)
+
+		x1 = float32(math.Max(0, math.Min(float64(x1), float64(originalWidth))))
+		y1 = float32(math.Max(0, math.Min(float64(y1), float64(originalHeight))))
+		x2 = float32(math.Max(0, math.Min(float64(x2), float64(originalWidth))))
+		y2 = float32(math.Max(0, math.Min(float64(y2), float64(originalHeight))))
+
+		results = append(results, utils.BoundingBox{
+			Label:      yo.Classes[classID],
+			ClassID:    classID,
+			Confidence: probability,
+			X1:         x1,
+			Y1:         y1,
+			X2:         x2,
+			Y2:         y2,
+		})
+	}
+
+	return LimitDetections(options.filterExcluded(results), options)
+}
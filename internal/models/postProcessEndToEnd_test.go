@@ -0,0 +1,133 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+func TestEndToEndPostProcess_Packed(t *testing.T) {
+	yolo := &EndToEndPostProcess{
+		Layout:        NMSLayoutPacked,
+		BoxFormat:     BoxXYXY,
+		MaxDetections: 3,
+		Classes:       []string{"person", "bicycle", "car"},
+	}
+
+	output := []float32{
+		10, 20, 50, 60, 0.9, 0, // person, high confidence
+		15, 25, 55, 65, 0.7, 1, // bicycle, medium confidence
+		100, 200, 150, 250, 0.4, 2, // car, below threshold
+	}
+
+	expectedResults := []utils.BoundingBox{
+		{Label: "person", Confidence: 0.9, X1: 10, Y1: 30, X2: 90, Y2: 110},
+		{Label: "bicycle", ClassID: 1, Confidence: 0.7, X1: 20, Y1: 40, X2: 100, Y2: 120},
+	}
+
+	results := yolo.PostProcess([][]float32{output}, 1280, 720, utils.Transform{ScaleX: 0.5, ScaleY: 0.5, PadX: 5, PadY: 5}, &PostProcessOptions{ScoreThreshold: 0.5, NMSThreshold: 0.4})
+
+	if len(results) != len(expectedResults) {
+		t.Fatalf("Expected %d results, got %d", len(expectedResults), len(results))
+	}
+	for i, result := range results {
+		if result != expectedResults[i] {
+			t.Errorf("Result %d mismatch. Expected %+v, got %+v", i, expectedResults[i], result)
+		}
+	}
+}
+
+func TestEndToEndPostProcess_StretchTransform(t *testing.T) {
+	yolo := &EndToEndPostProcess{
+		Layout:        NMSLayoutPacked,
+		BoxFormat:     BoxXYXY,
+		MaxDetections: 1,
+		Classes:       []string{"person"},
+	}
+
+	output := []float32{64, 64, 128, 128, 0.9, 0}
+	// A 1280x320 image stretched to 640x640.
+	transform := utils.Transform{ScaleX: 0.5, ScaleY: 2}
+
+	results := yolo.PostProcess([][]float32{output}, 1280, 320, transform, &PostProcessOptions{ScoreThreshold: 0.5})
+
+	expected := utils.BoundingBox{Label: "person", Confidence: 0.9, X1: 128, Y1: 32, X2: 256, Y2: 64}
+	if len(results) != 1 || results[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, results)
+	}
+}
+
+func TestEndToEndPostProcess_Split(t *testing.T) {
+	yolo := &EndToEndPostProcess{
+		Layout:        NMSLayoutSplit,
+		BoxFormat:     BoxXYWH,
+		MaxDetections: 4,
+		Classes:       []string{"person", "bicycle", "car"},
+	}
+
+	numDets := []float32{2}
+	boxes := []float32{
+		30, 40, 40, 40, // person, centre format
+		80, 90, 20, 20, // car
+		0, 0, 0, 0, // padding beyond num_dets
+		0, 0, 0, 0,
+	}
+	scores := []float32{0.8, 0.6, 0.99, 0.99}
+	classes := []float32{0, 2, 1, 1}
+
+	expectedResults := []utils.BoundingBox{
+		{Label: "person", Confidence: 0.8, X1: 10, Y1: 20, X2: 50, Y2: 60},
+		{Label: "car", ClassID: 2, Confidence: 0.6, X1: 70, Y1: 80, X2: 90, Y2: 100},
+	}
+
+	results := yolo.PostProcess([][]float32{numDets, boxes, scores, classes}, 640, 640, utils.Transform{ScaleX: 1.0, ScaleY: 1.0, PadX: 0, PadY: 0}, &PostProcessOptions{ScoreThreshold: 0.5, NMSThreshold: 0.4})
+
+	if len(results) != len(expectedResults) {
+		t.Fatalf("Expected %d results, got %d", len(expectedResults), len(results))
+	}
+	for i, result := range results {
+		if result != expectedResults[i] {
+			t.Errorf("Result %d mismatch. Expected %+v, got %+v", i, expectedResults[i], result)
+		}
+	}
+}
+
+func TestEndToEndPostProcess_SkipsUnknownClasses(t *testing.T) {
+	yolo := &EndToEndPostProcess{
+		Layout:  NMSLayoutPacked,
+		Classes: []string{"person"},
+	}
+
+	output := []float32{
+		10, 20, 50, 60, 0.9, 3,
+		10, 20, 50, 60, 0.9, -1,
+	}
+
+	results := yolo.PostProcess([][]float32{output}, 640, 640, utils.Transform{ScaleX: 1.0, ScaleY: 1.0, PadX: 0, PadY: 0}, &PostProcessOptions{ScoreThreshold: 0.5, NMSThreshold: 0.4})
+	if len(results) != 0 {
+		t.Errorf("Expected no results, got %+v", results)
+	}
+}
+
+func TestEndToEndPostProcess_SplitClampsNumDets(t *testing.T) {
+	yolo := &EndToEndPostProcess{
+		Layout:  NMSLayoutSplit,
+		Classes: []string{"person"},
+	}
+
+	boxes := []float32{10, 20, 50, 60}
+	scores := []float32{0.9}
+	classes := []float32{0}
+
+	for _, numDets := range []float32{100, -3} {
+		results := yolo.PostProcess([][]float32{{numDets}, boxes, scores, classes}, 640, 640, utils.Transform{ScaleX: 1.0, ScaleY: 1.0, PadX: 0, PadY: 0}, &PostProcessOptions{ScoreThreshold: 0.5, NMSThreshold: 0.4})
+
+		expected := 1
+		if numDets < 0 {
+			expected = 0
+		}
+		if len(results) != expected {
+			t.Errorf("num_dets %v: expected %d results, got %+v", numDets, expected, results)
+		}
+	}
+}
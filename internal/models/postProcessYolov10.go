@@ -12,11 +12,13 @@ type YOLOv10PostProcess struct {
 	Classes     []string
 }
 
-func (yo *YOLOv10PostProcess) PostProcess(output []float32,
+func (yo *YOLOv10PostProcess) PostProcess(outputs [][]float32,
 	originalWidth, originalHeight int,
-	scoreThreshold, nmsThreshold, scale float32,
-	dw, dh int,
+	transform utils.Transform,
+	options *PostProcessOptions,
 ) []utils.BoundingBox {
+	output := outputs[0]
+	thresholds := options.thresholds(yo.Classes)
 
 	results := make([]utils.BoundingBox, 0, yo.OutputShape)
 	for index := 0; index < len(output)/6; index++ {
@@ -27,17 +29,18 @@ func (yo *YOLOv10PostProcess) PostProcess(output []float32,
 		probability := output[index*6+4]
 		classID := int(output[index*6+5])
 
-		if probability < scoreThreshold {
+		if classID < 0 || classID >= len(thresholds) {
+			continue
+		}
+		if probability < thresholds[classID] {
 			continue
 		}
 
 		xc, yc := (x2+x1)/2.0, (y2+y1)/2.0
 		w, h := x2-x1, y2-y1
 
-		x1 = (xc - w/2 - float32(dw)) / scale
-		y1 = (yc - h/2 - float32(dh)) / scale
-		x2 = (xc + w/2 - float32(dw)) / scale
-		y2 = (yc + h/2 - float32(dh)) / scale
+		x1, y1 = transform.Restore(xc-w/2, yc-h/2)
+		x2, y2 = transform.Restore(xc+w/2, yc+h/2)
 
 		x1 = float32(math.Max(0, math.Min(float64(x1), float64(originalWidth))))
 		y1 = float32(math.Max(0, math.Min(float64(y1), float64(originalHeight))))
@@ -46,6 +49,7 @@ func (yo *YOLOv10PostProcess) PostProcess(output []float32,
 
 		results = append(results, utils.BoundingBox{
 			Label:      yo.Classes[classID],
+			ClassID:    classID,
 			Confidence: probability,
 			X1:         x1,
 			Y1:         y1,
@@ -54,5 +58,5 @@ func (yo *YOLOv10PostProcess) PostProcess(output []float32,
 		})
 	}
 
-	return results
+	return LimitDetections(options.filterExcluded(results), options)
 }
@@ -46,7 +46,8 @@ func TestYOLOv10PostProcess_PostProcess(t *testing.T) {
 		},
 	}
 
-	results := yolo.PostProcess(output, originalWidth, originalHeight, scoreThreshold, nmsThreshold, scale, dw, dh)
+	options := &PostProcessOptions{ScoreThreshold: scoreThreshold, NMSThreshold: nmsThreshold}
+	results := yolo.PostProcess([][]float32{output}, originalWidth, originalHeight, utils.Transform{ScaleX: scale, ScaleY: scale, PadX: dw, PadY: dh}, options)
 
 	if len(results) != len(expectedResults) {
 		t.Errorf("Expected %d results, got %d", len(expectedResults), len(results))
@@ -63,3 +64,21 @@ func TestYOLOv10PostProcess_PostProcess(t *testing.T) {
 		}
 	}
 }
+
+func TestYOLOv10PostProcess_SkipsUnknownClasses(t *testing.T) {
+	yolo := &YOLOv10PostProcess{
+		InputShape:  640,
+		OutputShape: 2,
+		Classes:     []string{"person"},
+	}
+
+	output := []float32{
+		10, 20, 50, 60, 0.9, 3,
+		10, 20, 50, 60, 0.9, -1,
+	}
+
+	results := yolo.PostProcess([][]float32{output}, 640, 640, utils.Transform{ScaleX: 1.0, ScaleY: 1.0, PadX: 0, PadY: 0}, &PostProcessOptions{ScoreThreshold: 0.5, NMSThreshold: 0.4})
+	if len(results) != 0 {
+		t.Errorf("Expected no results, got %+v", results)
+	}
+}
@@ -46,14 +46,14 @@ func TestPostProcess(t *testing.T) {
 	}
 
 	result := yolo.PostProcess(
-		output,
+		[][]float32{output},
 		640, // origWidth
 		480, // origHeight
-		0.5, // scoreThresh
-		0.5, // nmsThresh
-		1.0, // scale
-		0,   // dw
-		0,   // dh
+		utils.Transform{ScaleX: 1, ScaleY: 1},
+		&PostProcessOptions{
+			ScoreThreshold: 0.5,
+			NMSThreshold:   0.5,
+		},
 	)
 
 	if len(result) != len(expectedBoxes) {
@@ -67,3 +67,166 @@ func TestPostProcess(t *testing.T) {
 		}
 	}
 }
+
+func TestPostProcessClassAware(t *testing.T) {
+	classes := []string{"person", "backpack"}
+
+	// Two heavily overlapping boxes, one per class.
+	output := []float32{
+		100, 105, // xc
+		100, 105, // yc
+		50, 50, // w
+		80, 80, // h
+		0.9, 0.1, // person
+		0.1, 0.8, // backpack
+	}
+
+	tests := []struct {
+		name     string
+		agnostic bool
+		expected []string
+	}{
+		{name: "Class aware", agnostic: false, expected: []string{"person", "backpack"}},
+		{name: "Agnostic", agnostic: true, expected: []string{"person"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yolo := YOLOPostProcess{
+				OutputShape: 2,
+				ImageUtils:  &utils.ImageUtils{},
+				Classes:     classes,
+			}
+
+			options := &PostProcessOptions{ScoreThreshold: 0.5, NMSThreshold: 0.5, Agnostic: tt.agnostic}
+			result := yolo.PostProcess([][]float32{output}, 640, 480, utils.Transform{ScaleX: 1.0, ScaleY: 1.0, PadX: 0, PadY: 0}, options)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("Expected %d boxes, got %+v", len(tt.expected), result)
+			}
+			for i, label := range tt.expected {
+				if result[i].Label != label || result[i].ClassID != i {
+					t.Errorf("Box %d mismatch: expected %s, got %+v", i, label, result[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPostProcessWithSuppressor(t *testing.T) {
+	yolo := YOLOPostProcess{
+		OutputShape: 2,
+		ImageUtils:  &utils.ImageUtils{},
+		Classes:     []string{"person"},
+		Suppressor:  utils.NewSoftNMS(utils.DecayLinear),
+	}
+
+	// Two overlapping people; Soft-NMS keeps the second with a decayed score.
+	output := []float32{
+		100, 105, // xc
+		100, 100, // yc
+		50, 50, // w
+		80, 80, // h
+		0.9, 0.8, // person
+	}
+
+	transform := utils.Transform{ScaleX: 1.0, ScaleY: 1.0, PadX: 0, PadY: 0}
+
+	result := yolo.PostProcess([][]float32{output}, 640, 480, transform, &PostProcessOptions{ScoreThreshold: 0.1, NMSThreshold: 0.5})
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 boxes, got %+v", result)
+	}
+	if result[0].Confidence != 0.9 || result[1].Confidence >= 0.8 || result[1].Confidence < 0.1 {
+		t.Errorf("Expected second box to be decayed, got %+v", result)
+	}
+
+	// The decayed score falls below a 0.5 threshold, so the box is dropped.
+	result = yolo.PostProcess([][]float32{output}, 640, 480, transform, &PostProcessOptions{ScoreThreshold: 0.5, NMSThreshold: 0.5})
+	if len(result) != 1 || result[0].Confidence != 0.9 {
+		t.Errorf("Expected only the first box, got %+v", result)
+	}
+}
+
+func TestPostProcessExclusionBeforeSuppression(t *testing.T) {
+	yolo := YOLOPostProcess{
+		OutputShape: 4,
+		ImageUtils:  &utils.ImageUtils{},
+		Classes:     []string{"person"},
+	}
+
+	// A high scoring box on signage overlaps a real person, followed by two
+	// more people.
+	output := []float32{
+		100, 105, 300, 500, // xc
+		100, 100, 100, 100, // yc
+		50, 50, 50, 50, // w
+		80, 80, 80, 80, // h
+		0.9, 0.8, 0.7, 0.6, // person
+	}
+	// The mask is given in frame coordinates, the boxes are decoded in a
+	// slice placed 1000 pixels to the right.
+	sign := utils.ExclusionMask{Polygon: utils.Polygon{{X: 1090, Y: 90}, {X: 1101, Y: 90}, {X: 1101, Y: 110}, {X: 1090, Y: 110}}}
+	placement := func(box *utils.BoundingBox) { box.Translate(1000, 0) }
+	transform := utils.Transform{ScaleX: 1, ScaleY: 1}
+
+	tests := []struct {
+		name     string
+		masks    []utils.ExclusionMask
+		expected []float32
+	}{
+		{name: "Without masks", expected: []float32{0.9, 0.7, 0.6}},
+		{name: "Excluded box suppresses nothing", masks: []utils.ExclusionMask{sign}, expected: []float32{0.8, 0.7, 0.6}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := &PostProcessOptions{
+				ScoreThreshold: 0.5,
+				NMSThreshold:   0.5,
+				MaxDetections:  3,
+				ExclusionMasks: tt.masks,
+				Placement:      placement,
+			}
+			result := yolo.PostProcess([][]float32{output}, 640, 480, transform, options)
+
+			scores := make([]float32, len(result))
+			for i, box := range result {
+				scores[i] = box.Confidence
+			}
+			if !reflect.DeepEqual(scores, tt.expected) {
+				t.Errorf("expected scores %v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPostProcessExclusionBeforeCap(t *testing.T) {
+	yolo := YOLOPostProcess{
+		OutputShape: 4,
+		ImageUtils:  &utils.ImageUtils{},
+		Classes:     []string{"person"},
+	}
+
+	output := []float32{
+		50, 150, 250, 350, // xc
+		100, 100, 100, 100, // yc
+		40, 40, 40, 40, // w
+		40, 40, 40, 40, // h
+		0.9, 0.8, 0.7, 0.6, // person
+	}
+	masks := []utils.ExclusionMask{{Polygon: utils.Polygon{{X: 0, Y: 0}, {X: 100, Y: 0}, {X: 100, Y: 200}, {X: 0, Y: 200}}}}
+
+	for _, skipNMS := range []bool{false, true} {
+		options := &PostProcessOptions{ScoreThreshold: 0.5, NMSThreshold: 0.5, MaxDetections: 2, SkipNMS: skipNMS, ExclusionMasks: masks}
+		result := yolo.PostProcess([][]float32{output}, 640, 480, utils.Transform{ScaleX: 1, ScaleY: 1}, options)
+		if skipNMS {
+			// Raw candidates are not capped.
+			if len(result) != 3 {
+				t.Errorf("expected 3 raw candidates, got %+v", result)
+			}
+			continue
+		}
+		if len(result) != 2 || result[0].Confidence != 0.8 || result[1].Confidence != 0.7 {
+			t.Errorf("expected the two best valid boxes, got %+v", result)
+		}
+	}
+}
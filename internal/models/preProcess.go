@@ -6,13 +6,81 @@ import (
 	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
 )
 
+// ChannelOrder is the colour order of the model input channels.
+type ChannelOrder int
+
+const (
+	ChannelsRGB ChannelOrder = iota
+	ChannelsBGR
+)
+
+// TensorLayout is the memory layout of the model input.
+type TensorLayout int
+
+const (
+	// LayoutNCHW stores each channel as a plane.
+	LayoutNCHW TensorLayout = iota
+	// LayoutNHWC interleaves the channels of every pixel.
+	LayoutNHWC
+)
+
+// Normalization describes how pixels become input values:
+// (value/255 - mean) / std, or (value - mean) / std with Raw. The zero
+// value is the Ultralytics input: RGB, NCHW and values in 0-1.
+type Normalization struct {
+	Order  ChannelOrder
+	Layout TensorLayout
+	// Raw keeps pixel values in 0-255 instead of scaling them to 0-1.
+	Raw bool
+	// Mean and Std are given in the model's channel order. A zero Std is
+	// treated as 1.
+	Mean [3]float32
+	Std  [3]float32
+}
+
+// lookup returns the input value of every byte value for each model
+// channel.
+func (n *Normalization) lookup() *[3][256]float32 {
+	const div255 = 1.0 / 255.0
+	scale := float32(div255)
+	if n.Raw {
+		scale = 1
+	}
+
+	var table [3][256]float32
+	for c := range table {
+		std := n.Std[c]
+		if std == 0 {
+			std = 1
+		}
+		for v := range table[c] {
+			table[c][v] = (float32(v)*scale - n.Mean[c]) / std
+		}
+	}
+	return &table
+}
+
 type YOLOPreProcess struct {
-	InputShape int
-	ImageUtils utils.IImageUtils
+	InputShape    int
+	ImageUtils    utils.IImageUtils
+	Letterbox     utils.LetterboxOptions
+	Normalization Normalization
+	// Workers is the number of goroutines converting rows. Zero uses
+	// GOMAXPROCS.
+	Workers int
+
+	// table caches the lookup table of tableFor.
+	table    *[3][256]float32
+	tableFor Normalization
+	scratch  fusedScratch
 }
 
-func (yo *YOLOPreProcess) PreProcess(img image.Image, dst *[]float32) (float32, int, int) {
-	img, scale, dw, dh := yo.ImageUtils.Letterbox(img, yo.InputShape)
+func (yo *YOLOPreProcess) PreProcess(img image.Image, dst *[]float32) utils.Transform {
+	if transform, ok := yo.preProcessFused(img, *dst); ok {
+		return transform
+	}
+
+	img, transform := yo.ImageUtils.Letterbox(img, yo.InputShape, &yo.Letterbox)
 
 	// Get the specific image type for better performance
 	switch typedImg := img.(type) {
@@ -24,70 +92,77 @@ func (yo *YOLOPreProcess) PreProcess(img image.Image, dst *[]float32) (float32,
 		// Fallback for other image types
 		yo.processGeneric(img, dst)
 	}
-	return scale, dw, dh
+	return transform
 }
 
-func (yo *YOLOPreProcess) processRGBA(img *image.RGBA, dst *[]float32) {
-	channelSize := yo.InputShape * yo.InputShape
-	redChannel := (*dst)[0:channelSize]
-	greenChannel := (*dst)[channelSize : channelSize*2]
-	blueChannel := (*dst)[channelSize*2 : channelSize*3]
-
-	pixels := img.Pix
-	stride := img.Stride
-	const div255 = 1.0 / 255.0
-
-	for y := 0; y < yo.InputShape; y++ {
-		offset := y * stride
-		for x := 0; x < yo.InputShape; x++ {
-			i := y*yo.InputShape + x
-			pixelOffset := offset + x*4
-
-			redChannel[i] = float32(pixels[pixelOffset]) * div255
-			greenChannel[i] = float32(pixels[pixelOffset+1]) * div255
-			blueChannel[i] = float32(pixels[pixelOffset+2]) * div255
-		}
+// lookup returns the cached table of the current normalization.
+func (yo *YOLOPreProcess) lookup() *[3][256]float32 {
+	if yo.table == nil || yo.tableFor != yo.Normalization {
+		yo.table = yo.Normalization.lookup()
+		yo.tableFor = yo.Normalization
 	}
+	return yo.table
 }
 
-func (yo *YOLOPreProcess) processNRGBA(img *image.NRGBA, dst *[]float32) {
+// offsets returns, for each model channel, the source byte within an RGBA
+// pixel, where the channel starts in dst and the step between pixels.
+func (yo *YOLOPreProcess) offsets() (source, start [3]int, step int) {
+	source = [3]int{0, 1, 2}
+	if yo.Normalization.Order == ChannelsBGR {
+		source = [3]int{2, 1, 0}
+	}
+
+	if yo.Normalization.Layout == LayoutNHWC {
+		return source, [3]int{0, 1, 2}, 3
+	}
 	channelSize := yo.InputShape * yo.InputShape
-	redChannel := (*dst)[0:channelSize]
-	greenChannel := (*dst)[channelSize : channelSize*2]
-	blueChannel := (*dst)[channelSize*2 : channelSize*3]
+	return source, [3]int{0, channelSize, 2 * channelSize}, 1
+}
 
-	pixels := img.Pix
-	stride := img.Stride
-	const div255 = 1.0 / 255.0
+func (yo *YOLOPreProcess) processRGBA(img *image.RGBA, dst *[]float32) {
+	yo.processPix(img.Pix, img.Stride, dst)
+}
 
-	for y := 0; y < yo.InputShape; y++ {
-		offset := y * stride
-		for x := 0; x < yo.InputShape; x++ {
-			i := y*yo.InputShape + x
-			pixelOffset := offset + x*4
+func (yo *YOLOPreProcess) processNRGBA(img *image.NRGBA, dst *[]float32) {
+	yo.processPix(img.Pix, img.Stride, dst)
+}
 
-			redChannel[i] = float32(pixels[pixelOffset]) * div255
-			greenChannel[i] = float32(pixels[pixelOffset+1]) * div255
-			blueChannel[i] = float32(pixels[pixelOffset+2]) * div255
+// processPix converts 8-bit RGBA pixels, which RGBA and NRGBA share for
+// opaque images.
+func (yo *YOLOPreProcess) processPix(pixels []uint8, stride int, dst *[]float32) {
+	table := yo.lookup()
+	source, start, step := yo.offsets()
+	first := (*dst)[start[0]:]
+	second := (*dst)[start[1]:]
+	third := (*dst)[start[2]:]
+
+	yo.parallelRows(yo.workers(), rowFunc(func(_, from, to int) {
+		for y := from; y < to; y++ {
+			offset := y * stride
+			for x := 0; x < yo.InputShape; x++ {
+				i := (y*yo.InputShape + x) * step
+				pixelOffset := offset + x*4
+
+				first[i] = table[0][pixels[pixelOffset+source[0]]]
+				second[i] = table[1][pixels[pixelOffset+source[1]]]
+				third[i] = table[2][pixels[pixelOffset+source[2]]]
+			}
 		}
-	}
+	}))
 }
 
 func (yo *YOLOPreProcess) processGeneric(img image.Image, dst *[]float32) {
-	channelSize := yo.InputShape * yo.InputShape
-	redChannel := (*dst)[0:channelSize]
-	greenChannel := (*dst)[channelSize : channelSize*2]
-	blueChannel := (*dst)[channelSize*2 : channelSize*3]
-
-	const div255 = 1.0 / 255.0
+	table := yo.lookup()
+	source, start, step := yo.offsets()
 
 	for y := 0; y < yo.InputShape; y++ {
 		for x := 0; x < yo.InputShape; x++ {
-			i := y*yo.InputShape + x
+			i := (y*yo.InputShape + x) * step
 			r, g, b, _ := img.At(x, y).RGBA()
-			redChannel[i] = float32(r>>8) * div255
-			greenChannel[i] = float32(g>>8) * div255
-			blueChannel[i] = float32(b>>8) * div255
+			rgb := [3]uint32{r >> 8, g >> 8, b >> 8}
+			for c := range start {
+				(*dst)[start[c]+i] = table[c][rgb[source[c]]]
+			}
 		}
 	}
 }
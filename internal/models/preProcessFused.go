@@ -0,0 +1,248 @@
+package model
+
+import (
+	"image"
+	"runtime"
+	"slices"
+	"sync"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// minRowsPerWorker keeps small inputs from being split into goroutines that
+// cost more than the rows they convert.
+const minRowsPerWorker = 32
+
+// letterboxGeometry is implemented by image utils whose letterbox can be
+// computed without producing the letterboxed image.
+type letterboxGeometry interface {
+	Geometry(width, height, inputSize int, options *utils.LetterboxOptions) (utils.Transform, image.Point)
+}
+
+// fusedScratch holds the buffers of the last fused pass so they are only
+// reallocated when the image grows.
+type fusedScratch struct {
+	rows rowReader
+	// lines holds two converted source rows per worker.
+	lines   [][]uint8
+	first   []int
+	second  []int
+	weights []float32
+	// pass is the state of the running pass, kept here so that converting
+	// rows on the calling goroutine does not allocate.
+	pass fusedPass
+	wg   sync.WaitGroup
+}
+
+// fusedPass converts rows of one fused pass.
+type fusedPass struct {
+	yo                       *YOLOPreProcess
+	interpolation            utils.Interpolation
+	table                    *[3][256]float32
+	source                   [3]int
+	step                     int
+	padValues                [3]float32
+	first, second, third     []float32
+	left, right, top, bottom int
+	srcHeight, height        int
+}
+
+// rowConverter converts the input rows from to to, using the scratch of
+// worker.
+type rowConverter interface {
+	convertRows(worker, from, to int)
+}
+
+// rowFunc adapts a function to rowConverter.
+type rowFunc func(worker, from, to int)
+
+func (f rowFunc) convertRows(worker, from, to int) {
+	f(worker, from, to)
+}
+
+// preProcessFused letterboxes and normalizes img into dst in one pass,
+// sampling the source directly instead of building the resized and padded
+// images. It reports false when img or the interpolation is not supported,
+// and produces the same values as Letterbox followed by processPix.
+func (yo *YOLOPreProcess) preProcessFused(img image.Image, dst []float32) (utils.Transform, bool) {
+	geometry, ok := yo.ImageUtils.(letterboxGeometry)
+	if !ok {
+		return utils.Transform{}, false
+	}
+	interpolation := yo.Letterbox.Interpolation
+	if interpolation != utils.InterpolationBilinear && interpolation != utils.InterpolationNearest {
+		return utils.Transform{}, false
+	}
+	scratch := &yo.scratch
+	if !scratch.rows.reset(img) {
+		return utils.Transform{}, false
+	}
+	rows := &scratch.rows
+	rows.background, rows.composite = yo.Letterbox.BackgroundRGB()
+
+	srcWidth, srcHeight := img.Bounds().Dx(), img.Bounds().Dy()
+	transform, size := geometry.Geometry(srcWidth, srcHeight, yo.InputShape, &yo.Letterbox)
+	if srcWidth == 0 || srcHeight == 0 || size.X == 0 || size.Y == 0 {
+		return utils.Transform{}, false
+	}
+
+	table := yo.lookup()
+	source, start, step := yo.offsets()
+	pad := yo.Letterbox.PadRGB()
+	padValues := [3]float32{table[0][pad[source[0]]], table[1][pad[source[1]]], table[2][pad[source[2]]]}
+
+	workers := yo.workers()
+	scratch.lines = slices.Grow(scratch.lines[:0], 2*workers)[:2*workers]
+	for i := range scratch.lines {
+		scratch.lines[i] = resizeBytes(scratch.lines[i], srcWidth*4)
+	}
+	scratch.first = resizeInts(scratch.first, size.X)
+	if interpolation == utils.InterpolationBilinear {
+		scratch.second = resizeInts(scratch.second, size.X)
+		scratch.weights = resizeFloats(scratch.weights, size.X)
+		utils.BilinearTaps(srcWidth, scratch.first, scratch.second, scratch.weights)
+	} else {
+		for x := range scratch.first {
+			scratch.first[x] = utils.NearestTap(x, srcWidth, size.X)
+		}
+	}
+
+	scratch.pass = fusedPass{
+		yo:            yo,
+		interpolation: interpolation,
+		table:         table,
+		source:        source,
+		step:          step,
+		padValues:     padValues,
+		first:         dst[start[0]:],
+		second:        dst[start[1]:],
+		third:         dst[start[2]:],
+		left:          transform.PadX,
+		right:         transform.PadX + size.X,
+		top:           transform.PadY,
+		bottom:        transform.PadY + size.Y,
+		srcHeight:     srcHeight,
+		height:        size.Y,
+	}
+	yo.parallelRows(workers, &scratch.pass)
+	scratch.pass = fusedPass{}
+	return transform, true
+}
+
+func (p *fusedPass) convertRows(worker, from, to int) {
+	yo := p.yo
+	scratch := &yo.scratch
+	rows := &scratch.rows
+	table, source, step, padValues := p.table, p.source, p.step, p.padValues
+	first, second, third := p.first, p.second, p.third
+	left, right, top, bottom := p.left, p.right, p.top, p.bottom
+
+	line0, line1 := scratch.lines[2*worker], scratch.lines[2*worker+1]
+	for y := from; y < to; y++ {
+		row := y * yo.InputShape * step
+		if y < top || y >= bottom {
+			for x := 0; x < yo.InputShape; x++ {
+				i := row + x*step
+				first[i], second[i], third[i] = padValues[0], padValues[1], padValues[2]
+			}
+			continue
+		}
+
+		for x := 0; x < left; x++ {
+			i := row + x*step
+			first[i], second[i], third[i] = padValues[0], padValues[1], padValues[2]
+		}
+		for x := right; x < yo.InputShape; x++ {
+			i := row + x*step
+			first[i], second[i], third[i] = padValues[0], padValues[1], padValues[2]
+		}
+
+		if p.interpolation == utils.InterpolationNearest {
+			line := rows.row(utils.NearestTap(y-top, p.srcHeight, p.height), line0)
+			for x, sx := range scratch.first {
+				i := row + (left+x)*step
+				px := line[sx*4:]
+				first[i] = table[0][px[source[0]]]
+				second[i] = table[1][px[source[1]]]
+				third[i] = table[2][px[source[2]]]
+			}
+			continue
+		}
+
+		y0, y1, wy := utils.BilinearTap(y-top, p.srcHeight, p.height)
+		row0 := rows.row(y0, line0)
+		row1 := row0
+		if y1 != y0 {
+			row1 = rows.row(y1, line1)
+		}
+		for x, sx := range scratch.first {
+			i := row + (left+x)*step
+			i0, i1, wx := sx*4, scratch.second[x]*4, scratch.weights[x]
+			first[i] = table[0][lerpAt(row0, row1, i0+source[0], i1+source[0], wx, wy)]
+			second[i] = table[1][lerpAt(row0, row1, i0+source[1], i1+source[1], wx, wy)]
+			third[i] = table[2][lerpAt(row0, row1, i0+source[2], i1+source[2], wx, wy)]
+		}
+	}
+}
+
+// lerpAt interpolates the bytes at left and right of two source rows.
+func lerpAt(row0, row1 []uint8, left, right int, wx, wy float32) uint8 {
+	return utils.Lerp(row0[left], row0[right], row1[left], row1[right], wx, wy)
+}
+
+// workers returns the number of goroutines parallelRows uses.
+func (yo *YOLOPreProcess) workers() int {
+	workers := yo.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return max(min(workers, yo.InputShape/minRowsPerWorker), 1)
+}
+
+// parallelRows splits the rows of the input between workers goroutines
+// and waits for them to finish. A single worker runs on the calling
+// goroutine without allocating; otherwise each goroutine costs one small
+// allocation.
+func (yo *YOLOPreProcess) parallelRows(workers int, work rowConverter) {
+	rows := yo.InputShape
+	if workers == 1 {
+		work.convertRows(0, 0, rows)
+		return
+	}
+
+	wg := &yo.scratch.wg
+	chunk := (rows + workers - 1) / workers
+	for worker := range workers {
+		from, to := worker*chunk, min((worker+1)*chunk, rows)
+		if from >= to {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			work.convertRows(worker, from, to)
+		}()
+	}
+	wg.Wait()
+}
+
+func resizeInts(s []int, n int) []int {
+	if cap(s) < n {
+		return make([]int, n)
+	}
+	return s[:n]
+}
+
+func resizeFloats(s []float32, n int) []float32 {
+	if cap(s) < n {
+		return make([]float32, n)
+	}
+	return s[:n]
+}
+
+func resizeBytes(s []uint8, n int) []uint8 {
+	if cap(s) < n {
+		return make([]uint8, n)
+	}
+	return s[:n]
+}
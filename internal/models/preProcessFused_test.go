@@ -0,0 +1,276 @@
+package model
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// unfusedUtils hides Geometry so PreProcess builds the letterboxed image.
+type unfusedUtils struct {
+	utils.IImageUtils
+}
+
+func randomNRGBA(width, height int, seed int64) *image.NRGBA {
+	rng := rand.New(rand.NewSource(seed))
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	rng.Read(img.Pix)
+	return img
+}
+
+func opaqueRGBA(width, height int, seed int64) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	copy(img.Pix, randomNRGBA(width, height, seed).Pix)
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+	return img
+}
+
+// gradientNRGBA returns an opaque image with smooth colours, closer to a
+// photo than random noise.
+func gradientNRGBA(width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 255 / width), G: uint8(y * 255 / height), B: uint8(x + y), A: 255})
+		}
+	}
+	return img
+}
+
+// typedImages returns the pixels of src stored in every image type with a
+// fused fast path.
+func typedImages(src *image.NRGBA) map[string]image.Image {
+	rect := src.Rect
+	images := map[string]image.Image{
+		"NRGBA":   src,
+		"RGBA":    image.NewRGBA(rect),
+		"NRGBA64": image.NewNRGBA64(rect),
+		"RGBA64":  image.NewRGBA64(rect),
+		"Gray":    image.NewGray(rect),
+		"Gray16":  image.NewGray16(rect),
+	}
+	for _, ratio := range []image.YCbCrSubsampleRatio{
+		image.YCbCrSubsampleRatio444, image.YCbCrSubsampleRatio422, image.YCbCrSubsampleRatio420,
+		image.YCbCrSubsampleRatio440, image.YCbCrSubsampleRatio411, image.YCbCrSubsampleRatio410,
+	} {
+		ycbcr := image.NewYCbCr(rect, ratio)
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			for x := rect.Min.X; x < rect.Max.X; x++ {
+				c := src.NRGBAAt(x, y)
+				// Subsampled chroma keeps the last pixel of its block.
+				ycbcr.Y[ycbcr.YOffset(x, y)], ycbcr.Cb[ycbcr.COffset(x, y)], ycbcr.Cr[ycbcr.COffset(x, y)] = color.RGBToYCbCr(c.R, c.G, c.B)
+			}
+		}
+		images["YCbCr "+ratio.String()] = ycbcr
+	}
+
+	palette := color.Palette{}
+	for i := range 200 {
+		palette = append(palette, color.NRGBA{R: uint8(i), G: uint8(255 - i), B: uint8(i * 7), A: uint8(55 + i)})
+	}
+	paletted := image.NewPaletted(rect, palette)
+	for i := range paletted.Pix {
+		paletted.Pix[i] = src.Pix[i*4] % uint8(len(palette))
+	}
+	images["Paletted"] = paletted
+
+	images["RGB24"], images["BGR24"] = rawPacked(src, utils.FormatRGB24), rawPacked(src, utils.FormatBGR24)
+	images["NV12"], images["I420"] = rawPlanar(images["YCbCr "+image.YCbCrSubsampleRatio420.String()].(*image.YCbCr))
+
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			c := src.NRGBAAt(x, y)
+			for _, name := range []string{"RGBA", "NRGBA64", "RGBA64", "Gray", "Gray16"} {
+				images[name].(draw.Image).Set(x, y, c)
+			}
+		}
+	}
+	return images
+}
+
+// rawPacked stores the colours of src, ignoring alpha, in an RGB24 or
+// BGR24 frame.
+func rawPacked(src *image.NRGBA, format utils.PixelFormat) *utils.RawImage {
+	width, height := src.Rect.Dx(), src.Rect.Dy()
+	buf := make([]byte, 0, width*height*3)
+	for i := 0; i < len(src.Pix); i += 4 {
+		if format == utils.FormatBGR24 {
+			buf = append(buf, src.Pix[i+2], src.Pix[i+1], src.Pix[i])
+		} else {
+			buf = append(buf, src.Pix[i:i+3]...)
+		}
+	}
+	img, _ := utils.NewRawImage(buf, width, height, width*3, format)
+	return img
+}
+
+// rawPlanar stores a 4:2:0 image with an even width as NV12 and I420
+// frames.
+func rawPlanar(ycbcr *image.YCbCr) (*utils.RawImage, *utils.RawImage) {
+	width, height := ycbcr.Rect.Dx(), ycbcr.Rect.Dy()
+	i420 := slices.Concat(ycbcr.Y, ycbcr.Cb, ycbcr.Cr)
+	nv12 := slices.Clone(ycbcr.Y)
+	for i := range ycbcr.Cb {
+		nv12 = append(nv12, ycbcr.Cb[i], ycbcr.Cr[i])
+	}
+
+	nv12Image, _ := utils.NewRawImage(nv12, width, height, ycbcr.YStride, utils.FormatNV12)
+	i420Image, _ := utils.NewRawImage(i420, width, height, ycbcr.YStride, utils.FormatI420)
+	return nv12Image, i420Image
+}
+
+func TestPreProcessFusedImageTypes(t *testing.T) {
+	const inputShape = 64
+	for name, img := range typedImages(randomNRGBA(90, 53, 8)) {
+		// Sub-images start at odd coordinates to exercise chroma offsets.
+		sub := img.(interface {
+			SubImage(image.Rectangle) image.Image
+		}).SubImage(image.Rect(3, 5, 87, 50))
+		if !new(rowReader).reset(sub) {
+			t.Errorf("%s: expected a fused fast path", name)
+		}
+
+		for _, letterbox := range []utils.LetterboxOptions{
+			{},
+			{Interpolation: utils.InterpolationNearest},
+			{Background: color.White},
+		} {
+			fused := &YOLOPreProcess{InputShape: inputShape, ImageUtils: &utils.ImageUtils{}, Letterbox: letterbox, Workers: 2}
+			unfused := &YOLOPreProcess{InputShape: inputShape, ImageUtils: unfusedUtils{&utils.ImageUtils{}}, Letterbox: letterbox}
+
+			got := make([]float32, 3*inputShape*inputShape)
+			want := make([]float32, len(got))
+			fused.PreProcess(sub, &got)
+			unfused.PreProcess(sub, &want)
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("%s/%+v: value %d mismatch: expected %f, got %f", name, letterbox, i, want[i], got[i])
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestPreProcessFusedMatchesLetterbox(t *testing.T) {
+	images := map[string]image.Image{
+		"NRGBA landscape": randomNRGBA(173, 97, 1),
+		"NRGBA portrait":  randomNRGBA(40, 90, 2),
+		"RGBA sub-image":  opaqueRGBA(120, 120, 3).SubImage(image.Rect(17, 9, 101, 77)),
+		"RGBA translucent": func() image.Image {
+			img := image.NewRGBA(image.Rect(0, 0, 50, 30))
+			copy(img.Pix, randomNRGBA(50, 30, 4).Pix)
+			return img
+		}(),
+		"Same size": randomNRGBA(64, 64, 5),
+	}
+	letterboxes := map[string]utils.LetterboxOptions{
+		"Default":     {},
+		"Nearest":     {Interpolation: utils.InterpolationNearest},
+		"Top-left":    {Padding: utils.PadTopLeft, PadColor: color.RGBA{R: 10, G: 20, B: 30, A: 255}},
+		"Stretch":     {Stretch: true},
+		"No scale-up": {DisableScaleUp: true, Interpolation: utils.InterpolationNearest},
+		"Background":  {Background: color.RGBA{R: 200, G: 100, B: 50, A: 255}},
+	}
+	normalizations := map[string]Normalization{
+		"Default": {},
+		"BGR NHWC": {
+			Order: ChannelsBGR, Layout: LayoutNHWC,
+			Mean: [3]float32{0.406, 0.456, 0.485}, Std: [3]float32{0.225, 0.224, 0.229},
+		},
+	}
+
+	const inputShape = 64
+	for imageName, img := range images {
+		for letterboxName, letterbox := range letterboxes {
+			for normalizationName, normalization := range normalizations {
+				fused := &YOLOPreProcess{InputShape: inputShape, ImageUtils: &utils.ImageUtils{}, Letterbox: letterbox, Normalization: normalization, Workers: 3}
+				unfused := &YOLOPreProcess{InputShape: inputShape, ImageUtils: unfusedUtils{&utils.ImageUtils{}}, Letterbox: letterbox, Normalization: normalization}
+
+				got := make([]float32, 3*inputShape*inputShape)
+				want := make([]float32, len(got))
+				gotTransform := fused.PreProcess(img, &got)
+				wantTransform := unfused.PreProcess(img, &want)
+
+				name := imageName + "/" + letterboxName + "/" + normalizationName
+				if gotTransform != wantTransform {
+					t.Errorf("%s: expected transform %+v, got %+v", name, wantTransform, gotTransform)
+				}
+				for i := range want {
+					if got[i] != want[i] {
+						t.Errorf("%s: value %d mismatch: expected %f, got %f", name, i, want[i], got[i])
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestPreProcessFusedReusesScratch(t *testing.T) {
+	yo := &YOLOPreProcess{InputShape: 64, ImageUtils: &utils.ImageUtils{}, Workers: 1}
+	img := randomNRGBA(128, 96, 6)
+	dst := make([]float32, 3*64*64)
+
+	yo.PreProcess(img, &dst)
+	allocs := testing.AllocsPerRun(10, func() {
+		yo.PreProcess(img, &dst)
+	})
+	if allocs > 0 {
+		t.Errorf("expected no allocations per call, got %.0f", allocs)
+	}
+}
+
+func BenchmarkPreProcess(b *testing.B) {
+	img := gradientNRGBA(1280, 720)
+	benchmarks := []struct {
+		name       string
+		imageUtils utils.IImageUtils
+		workers    int
+	}{
+		{name: "Fused", imageUtils: &utils.ImageUtils{}},
+		{name: "FusedSerial", imageUtils: &utils.ImageUtils{}, workers: 1},
+		{name: "Unfused", imageUtils: unfusedUtils{&utils.ImageUtils{}}, workers: 1},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			yo := &YOLOPreProcess{InputShape: 640, ImageUtils: bm.imageUtils, Workers: bm.workers}
+			dst := make([]float32, 3*640*640)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				yo.PreProcess(img, &dst)
+			}
+		})
+	}
+}
+
+func BenchmarkPreProcessImageTypes(b *testing.B) {
+	for name, img := range typedImages(gradientNRGBA(1280, 720)) {
+		for _, fused := range []bool{true, false} {
+			imageUtils := utils.IImageUtils(&utils.ImageUtils{})
+			variant := "Fused"
+			if !fused {
+				imageUtils, variant = unfusedUtils{imageUtils}, "Unfused"
+			}
+
+			b.Run(name+"/"+variant, func(b *testing.B) {
+				yo := &YOLOPreProcess{InputShape: 640, ImageUtils: imageUtils, Workers: 1}
+				dst := make([]float32, 3*640*640)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for range b.N {
+					yo.PreProcess(img, &dst)
+				}
+			})
+		}
+	}
+}
@@ -3,21 +3,25 @@ package model
 import (
 	"image"
 	"image/color"
+	"math"
 	"reflect"
 	"testing"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
 )
 
 type MockImageUtils struct {
-	LetterboxFunc func(img image.Image, inputSize int) (image.Image, float32, int, int)
+	LetterboxFunc func(img image.Image, inputSize int, options *utils.LetterboxOptions) (image.Image, utils.Transform)
 	NMSBoxesFunc  func(boxes *[]image.Rectangle, scores *[]float32, scoreThreshold, nmsThreshold float32) *[]int
+	BatchedFunc   func(boxes *[]utils.Box, scores *[]float32, classIDs *[]int, scoreThreshold, nmsThreshold float32, agnostic bool, maxDetections int) *[]int
 	IouFunc       func(box1, box2 image.Rectangle) float64
 }
 
-func (m *MockImageUtils) Letterbox(img image.Image, inputSize int) (image.Image, float32, int, int) {
+func (m *MockImageUtils) Letterbox(img image.Image, inputSize int, options *utils.LetterboxOptions) (image.Image, utils.Transform) {
 	if m.LetterboxFunc != nil {
-		return m.LetterboxFunc(img, inputSize)
+		return m.LetterboxFunc(img, inputSize, options)
 	}
-	return nil, 0, 0, 0
+	return nil, utils.Transform{}
 }
 
 func (m *MockImageUtils) NMSBoxes(boxes *[]image.Rectangle, scores *[]float32, scoreThreshold, nmsThreshold float32) *[]int {
@@ -27,6 +31,13 @@ func (m *MockImageUtils) NMSBoxes(boxes *[]image.Rectangle, scores *[]float32, s
 	return &[]int{}
 }
 
+func (m *MockImageUtils) NMSBoxesBatched(boxes *[]utils.Box, scores *[]float32, classIDs *[]int, scoreThreshold, nmsThreshold float32, agnostic bool, maxDetections int) *[]int {
+	if m.BatchedFunc != nil {
+		return m.BatchedFunc(boxes, scores, classIDs, scoreThreshold, nmsThreshold, agnostic, maxDetections)
+	}
+	return &[]int{}
+}
+
 func (m *MockImageUtils) Iou(box1, box2 image.Rectangle) float64 {
 	if m.IouFunc != nil {
 		return m.IouFunc(box1, box2)
@@ -48,8 +59,8 @@ func TestPreProcess(t *testing.T) {
 	// Mock the Letterbox method
 	inputSize := 128
 	mockedImage := image.NewRGBA(image.Rect(0, 0, inputSize, inputSize))
-	mockUtils.LetterboxFunc = func(img image.Image, inputSize int) (image.Image, float32, int, int) {
-		return mockedImage, 1.28, 10, 10
+	mockUtils.LetterboxFunc = func(img image.Image, inputSize int, options *utils.LetterboxOptions) (image.Image, utils.Transform) {
+		return mockedImage, utils.Transform{ScaleX: 1.28, ScaleY: 1.28, PadX: 10, PadY: 10}
 	}
 
 	// Instantiate YOLOPreProcess
@@ -59,17 +70,17 @@ func TestPreProcess(t *testing.T) {
 	}
 
 	dst := make([]float32, inputSize*inputSize*3) // R, G, B channels
-	scale, dw, dh := preprocessor.PreProcess(img, &dst)
+	transform := preprocessor.PreProcess(img, &dst)
 
 	// Assertions
-	if scale != 1.28 {
-		t.Errorf("Expected scale to be 1.28, got %f", scale)
+	if transform.ScaleX != 1.28 || transform.ScaleY != 1.28 {
+		t.Errorf("Expected scale to be 1.28, got %f, %f", transform.ScaleX, transform.ScaleY)
 	}
-	if dw != 10 {
-		t.Errorf("Expected dw to be 10, got %d", dw)
+	if transform.PadX != 10 {
+		t.Errorf("Expected dw to be 10, got %d", transform.PadX)
 	}
-	if dh != 10 {
-		t.Errorf("Expected dh to be 10, got %d", dh)
+	if transform.PadY != 10 {
+		t.Errorf("Expected dh to be 10, got %d", transform.PadY)
 	}
 }
 
@@ -163,8 +174,8 @@ func TestPreProcessWithNRGBA(t *testing.T) {
 	// Mock the Letterbox method
 	inputSize := 128
 	mockedImage := image.NewNRGBA(image.Rect(0, 0, inputSize, inputSize))
-	mockUtils.LetterboxFunc = func(img image.Image, inputSize int) (image.Image, float32, int, int) {
-		return mockedImage, 1.28, 10, 10
+	mockUtils.LetterboxFunc = func(img image.Image, inputSize int, options *utils.LetterboxOptions) (image.Image, utils.Transform) {
+		return mockedImage, utils.Transform{ScaleX: 1.28, ScaleY: 1.28, PadX: 10, PadY: 10}
 	}
 
 	// Instantiate YOLOPreProcess
@@ -174,17 +185,17 @@ func TestPreProcessWithNRGBA(t *testing.T) {
 	}
 
 	dst := make([]float32, inputSize*inputSize*3) // R, G, B channels
-	scale, dw, dh := preprocessor.PreProcess(img, &dst)
+	transform := preprocessor.PreProcess(img, &dst)
 
 	// Assertions
-	if scale != 1.28 {
-		t.Errorf("Expected scale to be 1.28, got %f", scale)
+	if transform.ScaleX != 1.28 || transform.ScaleY != 1.28 {
+		t.Errorf("Expected scale to be 1.28, got %f, %f", transform.ScaleX, transform.ScaleY)
 	}
-	if dw != 10 {
-		t.Errorf("Expected dw to be 10, got %d", dw)
+	if transform.PadX != 10 {
+		t.Errorf("Expected dw to be 10, got %d", transform.PadX)
 	}
-	if dh != 10 {
-		t.Errorf("Expected dh to be 10, got %d", dh)
+	if transform.PadY != 10 {
+		t.Errorf("Expected dh to be 10, got %d", transform.PadY)
 	}
 }
 
@@ -202,8 +213,8 @@ func TestPreProcessWithFallback(t *testing.T) {
 	// Mock the Letterbox method
 	inputSize := 128
 	mockedImage := image.NewGray(image.Rect(0, 0, inputSize, inputSize))
-	mockUtils.LetterboxFunc = func(img image.Image, inputSize int) (image.Image, float32, int, int) {
-		return mockedImage, 1.28, 10, 10
+	mockUtils.LetterboxFunc = func(img image.Image, inputSize int, options *utils.LetterboxOptions) (image.Image, utils.Transform) {
+		return mockedImage, utils.Transform{ScaleX: 1.28, ScaleY: 1.28, PadX: 10, PadY: 10}
 	}
 
 	// Instantiate YOLOPreProcess
@@ -213,16 +224,122 @@ func TestPreProcessWithFallback(t *testing.T) {
 	}
 
 	dst := make([]float32, inputSize*inputSize*3) // R, G, B channels
-	scale, dw, dh := preprocessor.PreProcess(img, &dst)
+	transform := preprocessor.PreProcess(img, &dst)
 
 	// Assertions
-	if scale != 1.28 {
-		t.Errorf("Expected scale to be 1.28, got %f", scale)
+	if transform.ScaleX != 1.28 || transform.ScaleY != 1.28 {
+		t.Errorf("Expected scale to be 1.28, got %f, %f", transform.ScaleX, transform.ScaleY)
+	}
+	if transform.PadX != 10 {
+		t.Errorf("Expected dw to be 10, got %d", transform.PadX)
 	}
-	if dw != 10 {
-		t.Errorf("Expected dw to be 10, got %d", dw)
+	if transform.PadY != 10 {
+		t.Errorf("Expected dh to be 10, got %d", transform.PadY)
 	}
-	if dh != 10 {
-		t.Errorf("Expected dh to be 10, got %d", dh)
+}
+
+// referenceNormalize is the straightforward per-pixel definition of the
+// normalization the fast paths must match.
+func referenceNormalize(img image.Image, size int, n Normalization) []float64 {
+	result := make([]float64, size*size*3)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			rgb := [3]float64{float64(r >> 8), float64(g >> 8), float64(b >> 8)}
+			for c := 0; c < 3; c++ {
+				value := rgb[c]
+				if n.Order == ChannelsBGR {
+					value = rgb[2-c]
+				}
+				if !n.Raw {
+					value /= 255
+				}
+				std := float64(n.Std[c])
+				if std == 0 {
+					std = 1
+				}
+				value = (value - float64(n.Mean[c])) / std
+
+				index := c*size*size + y*size + x
+				if n.Layout == LayoutNHWC {
+					index = (y*size+x)*3 + c
+				}
+				result[index] = value
+			}
+		}
+	}
+	return result
+}
+
+func TestNormalization(t *testing.T) {
+	const size = 16
+	rgba := image.NewRGBA(image.Rect(0, 0, size, size))
+	nrgba := image.NewNRGBA(image.Rect(0, 0, size, size))
+	gray := image.NewGray(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			c := color.RGBA{R: uint8(x * 16), G: uint8(y * 16), B: uint8(255 - x*y), A: 255}
+			rgba.SetRGBA(x, y, c)
+			nrgba.Set(x, y, c)
+			gray.Set(x, y, c)
+		}
+	}
+	images := map[string]image.Image{"RGBA": rgba, "NRGBA": nrgba, "Gray": gray}
+
+	imageNet := Normalization{Mean: [3]float32{0.485, 0.456, 0.406}, Std: [3]float32{0.229, 0.224, 0.225}}
+	var normalizations []Normalization
+	for _, order := range []ChannelOrder{ChannelsRGB, ChannelsBGR} {
+		for _, layout := range []TensorLayout{LayoutNCHW, LayoutNHWC} {
+			for _, raw := range []bool{false, true} {
+				plain := Normalization{Order: order, Layout: layout, Raw: raw}
+				withStats := imageNet
+				withStats.Order, withStats.Layout, withStats.Raw = order, layout, raw
+				if raw {
+					withStats.Mean = [3]float32{123.675, 116.28, 103.53}
+					withStats.Std = [3]float32{58.395, 57.12, 57.375}
+				}
+				normalizations = append(normalizations, plain, withStats)
+			}
+		}
+	}
+
+	mockUtils := &MockImageUtils{
+		LetterboxFunc: func(img image.Image, inputSize int, options *utils.LetterboxOptions) (image.Image, utils.Transform) {
+			return img, utils.Transform{ScaleX: 1, ScaleY: 1}
+		},
+	}
+
+	for name, img := range images {
+		for _, n := range normalizations {
+			preprocessor := YOLOPreProcess{InputShape: size, ImageUtils: mockUtils, Normalization: n}
+			dst := make([]float32, size*size*3)
+			preprocessor.PreProcess(img, &dst)
+
+			expected := referenceNormalize(img, size, n)
+			for i := range expected {
+				if math.Abs(float64(dst[i])-expected[i]) > 1e-4 {
+					t.Errorf("%s %+v: value %d expected %f, got %f", name, n, i, expected[i], dst[i])
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestNormalizationTableFollowsChanges(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	img.SetRGBA(0, 0, color.RGBA{R: 255, A: 255})
+	preprocessor := YOLOPreProcess{InputShape: 1}
+
+	dst := make([]float32, 3)
+	preprocessor.processRGBA(img, &dst)
+	if dst[0] != 1 {
+		t.Fatalf("expected 1, got %f", dst[0])
+	}
+
+	preprocessor.Normalization.Raw = true
+	preprocessor.processRGBA(img, &dst)
+	if dst[0] != 255 {
+		t.Errorf("expected the raw value after changing the normalization, got %f", dst[0])
 	}
 }
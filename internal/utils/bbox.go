@@ -1,14 +1,95 @@
 package utils
 
-import "fmt"
+import (
+	"fmt"
+	"image"
+	"math"
+)
 
+// BoundingBox is a detection in absolute image pixels.
 type BoundingBox struct {
-	Label          string
-	Confidence     float32
-	X1, Y1, X2, Y2 float32
+	Label      string  `json:"label"`
+	ClassID    int     `json:"class_id"`
+	Confidence float32 `json:"confidence"`
+	X1         float32 `json:"x1"`
+	Y1         float32 `json:"y1"`
+	X2         float32 `json:"x2"`
+	Y2         float32 `json:"y2"`
+}
+
+// Classification is one label predicted for a whole image or crop.
+type Classification struct {
+	Label      string
+	ClassID    int
+	Confidence float32
 }
 
 func (b *BoundingBox) String() string {
 	return fmt.Sprintf("Object %s (confidence %f): (%f, %f), (%f, %f)",
 		b.Label, b.Confidence, b.X1, b.Y1, b.X2, b.Y2)
 }
+
+func (b *BoundingBox) Width() float32 {
+	return max(0, b.X2-b.X1)
+}
+
+func (b *BoundingBox) Height() float32 {
+	return max(0, b.Y2-b.Y1)
+}
+
+func (b *BoundingBox) Area() float32 {
+	return b.Width() * b.Height()
+}
+
+// IoU returns the intersection over union of two boxes.
+func (b *BoundingBox) IoU(other *BoundingBox) float32 {
+	interWidth := min(b.X2, other.X2) - max(b.X1, other.X1)
+	interHeight := min(b.Y2, other.Y2) - max(b.Y1, other.Y1)
+	if interWidth <= 0 || interHeight <= 0 {
+		return 0
+	}
+
+	interArea := interWidth * interHeight
+	unionArea := b.Area() + other.Area() - interArea
+	if unionArea <= 0 {
+		return 0
+	}
+	return interArea / unionArea
+}
+
+// IoS returns the intersection over the area of the smaller box.
+func (b *BoundingBox) IoS(other *BoundingBox) float32 {
+	interWidth := min(b.X2, other.X2) - max(b.X1, other.X1)
+	interHeight := min(b.Y2, other.Y2) - max(b.Y1, other.Y1)
+	if interWidth <= 0 || interHeight <= 0 {
+		return 0
+	}
+
+	smallerArea := min(b.Area(), other.Area())
+	if smallerArea <= 0 {
+		return 0
+	}
+	return interWidth * interHeight / smallerArea
+}
+
+// Translate moves the box by dx, dy.
+func (b *BoundingBox) Translate(dx, dy float32) {
+	b.X1 += dx
+	b.Y1 += dy
+	b.X2 += dx
+	b.Y2 += dy
+}
+
+// PaddedRect returns the pixel rectangle of the box grown by padding, a
+// fraction of its width and height on every side, clamped to bounds.
+func (b *BoundingBox) PaddedRect(padding float64, bounds image.Rectangle) image.Rectangle {
+	padX := float64(b.Width()) * padding
+	padY := float64(b.Height()) * padding
+	rect := image.Rect(
+		int(math.Floor(float64(b.X1)-padX)),
+		int(math.Floor(float64(b.Y1)-padY)),
+		int(math.Ceil(float64(b.X2)+padX)),
+		int(math.Ceil(float64(b.Y2)+padY)),
+	)
+	return rect.Intersect(bounds)
+}
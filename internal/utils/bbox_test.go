@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"image"
 	"testing"
 )
 
@@ -57,3 +58,44 @@ func TestBoundingBoxString(t *testing.T) {
 		})
 	}
 }
+
+func TestPaddedRect(t *testing.T) {
+	tests := []struct {
+		name     string
+		box      BoundingBox
+		padding  float64
+		expected image.Rectangle
+	}{
+		{
+			name:     "No padding",
+			box:      BoundingBox{X1: 10.2, Y1: 20.7, X2: 30.5, Y2: 40},
+			expected: image.Rect(10, 20, 31, 40),
+		},
+		{
+			name:     "Padding",
+			box:      BoundingBox{X1: 20, Y1: 20, X2: 40, Y2: 60},
+			padding:  0.25,
+			expected: image.Rect(15, 10, 45, 70),
+		},
+		{
+			name:     "Clamped to image",
+			box:      BoundingBox{X1: 0, Y1: 80, X2: 20, Y2: 100},
+			padding:  0.5,
+			expected: image.Rect(0, 70, 30, 100),
+		},
+		{
+			name:     "Outside image",
+			box:      BoundingBox{X1: 120, Y1: 10, X2: 140, Y2: 20},
+			expected: image.Rectangle{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rect := tt.box.PaddedRect(tt.padding, image.Rect(0, 0, 100, 100))
+			if rect != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, rect)
+			}
+		})
+	}
+}
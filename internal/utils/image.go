@@ -2,88 +2,72 @@ package utils
 
 import (
 	"image"
-	"image/color"
-	"math"
 
 	"github.com/disintegration/imaging"
 )
 
 type IImageUtils interface {
-	Letterbox(img image.Image, inputSize int) (image.Image, float32, int, int)
+	Letterbox(img image.Image, inputSize int, options *LetterboxOptions) (image.Image, Transform)
 	NMSBoxes(boxes *[]image.Rectangle, scores *[]float32, scoreThreshold, nmsThreshold float32) *[]int
+	NMSBoxesBatched(boxes *[]Box, scores *[]float32, classIDs *[]int, scoreThreshold, nmsThreshold float32, agnostic bool, maxDetections int) *[]int
 	Iou(box1, box2 image.Rectangle) float64
 }
 
-type ImageUtils struct{}
+// ImageUtils keeps NMS buffers between calls, so a value must not be
+// shared between goroutines.
+type ImageUtils struct {
+	nms     NMS
+	indices []int
+}
 
 type Size struct {
 	Width  int
 	Height int
 }
 
-func (i *ImageUtils) Letterbox(img image.Image, inputSize int) (image.Image, float32, int, int) {
-	origWidth := img.Bounds().Dx()
-	origHeight := img.Bounds().Dy()
-
-	scale := math.Min(float64(inputSize)/float64(origWidth), float64(inputSize)/float64(origHeight))
-	newWidth := int(math.Round(float64(origWidth) * scale))
-	newHeight := int(math.Round(float64(origHeight) * scale))
-
-	dw := (inputSize - newWidth) / 2
-	dh := (inputSize - newHeight) / 2
-
-	resizedImg := imaging.Resize(img, newWidth, newHeight, imaging.NearestNeighbor)
-	paddedImg := imaging.New(inputSize, inputSize, color.RGBA{114, 114, 114, 255})
-	paddedImg = imaging.Paste(paddedImg, resizedImg, image.Pt(dw, dh))
-
-	return paddedImg, float32(scale), dw, dh
+// SubImage returns the part of img inside rect. Pixels are shared with img
+// when the image type supports it; otherwise they are copied into a new
+// image whose bounds start at the origin.
+func SubImage(img image.Image, rect image.Rectangle) image.Image {
+	rect = rect.Intersect(img.Bounds())
+	if sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(rect)
+	}
+	return imaging.Crop(img, rect)
 }
 
+// NMSBoxes runs greedy NMS on integer rectangles and returns the kept
+// indices ordered by descending score.
 func (iu *ImageUtils) NMSBoxes(boxes *[]image.Rectangle, scores *[]float32, scoreThreshold, nmsThreshold float32) *[]int {
-	filteredBoxes := []image.Rectangle{}
-	filteredScores := []float32{}
-	indices := []int{}
-
-	for idx, score := range *scores {
-		if score > scoreThreshold {
-			filteredBoxes = append(filteredBoxes, (*boxes)[idx])
-			filteredScores = append(filteredScores, score)
-			indices = append(indices, idx)
+	floatBoxes := make([]Box, len(*boxes))
+	for idx, box := range *boxes {
+		floatBoxes[idx] = Box{
+			X1: float32(box.Min.X), Y1: float32(box.Min.Y),
+			X2: float32(box.Max.X), Y2: float32(box.Max.Y),
 		}
 	}
 
-	selectedIndices := []int{}
-	for len(indices) > 0 {
-		maxIdx := 0
-		for i, score := range filteredScores {
-			if score > filteredScores[maxIdx] {
-				maxIdx = i
-			}
-		}
-
-		selectedIndices = append(selectedIndices, indices[maxIdx])
-
-		currentBox := filteredBoxes[maxIdx]
-		newIndices := []int{}
-		newBoxes := []image.Rectangle{}
-		newScores := []float32{}
-
-		for i, idx := range indices {
-			if i != maxIdx {
-				if iu.Iou(currentBox, filteredBoxes[i]) < float64(nmsThreshold) {
-					newIndices = append(newIndices, idx)
-					newBoxes = append(newBoxes, filteredBoxes[i])
-					newScores = append(newScores, filteredScores[i])
-				}
-			}
-		}
+	indices := iu.nms.Run(floatBoxes, *scores, nil, scoreThreshold, nmsThreshold, 0)
+	selectedIndices := make([]int, len(indices))
+	copy(selectedIndices, indices)
+	return &selectedIndices
+}
 
-		indices = newIndices
-		filteredBoxes = newBoxes
-		filteredScores = newScores
+// NMSBoxesBatched runs NMS separately for every class unless agnostic is
+// set. At most maxDetections indices are returned when it is positive. The
+// returned indices are only valid until the next call.
+func (iu *ImageUtils) NMSBoxesBatched(boxes *[]Box, scores *[]float32, classIDs *[]int,
+	scoreThreshold, nmsThreshold float32, agnostic bool, maxDetections int,
+) *[]int {
+	var classes []int
+	if !agnostic {
+		classes = *classIDs
 	}
 
-	return &selectedIndices
+	iu.indices = iu.nms.Run(*boxes, *scores, classes, scoreThreshold, nmsThreshold, maxDetections)
+	return &iu.indices
 }
 
 func (i *ImageUtils) Iou(box1, box2 image.Rectangle) float64 {
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"image"
+	"image/color"
 	"math"
 	"testing"
 )
@@ -11,20 +12,12 @@ func TestLetterbox(t *testing.T) {
 	img := image.NewRGBA(image.Rect(0, 0, 200, 100))
 
 	inputSize := 300
-	expectedScale := float32(1.5)
-	expectedDW := 0
-	expectedDH := 75
+	expected := Transform{ScaleX: 1.5, ScaleY: 1.5, PadX: 0, PadY: 75}
 
-	resultImg, scale, dw, dh := imgUtils.Letterbox(img, inputSize)
+	resultImg, transform := imgUtils.Letterbox(img, inputSize, &LetterboxOptions{})
 
-	if scale != expectedScale {
-		t.Errorf("expected scale %f, got %f", expectedScale, scale)
-	}
-	if dw != expectedDW {
-		t.Errorf("expected dw %d, got %d", expectedDW, dw)
-	}
-	if dh != expectedDH {
-		t.Errorf("expected dh %d, got %d", expectedDH, dh)
+	if transform != expected {
+		t.Errorf("expected transform %+v, got %+v", expected, transform)
 	}
 
 	bounds := resultImg.Bounds()
@@ -70,3 +63,66 @@ func TestNMSBoxes(t *testing.T) {
 		}
 	}
 }
+
+func TestNMSBoxesBatched(t *testing.T) {
+	imgUtils := ImageUtils{}
+
+	boxes := []Box{
+		{X1: 0, Y1: 0, X2: 100, Y2: 100},     // person
+		{X1: 10, Y1: 10, X2: 110, Y2: 110},   // backpack overlapping the person
+		{X1: 5, Y1: 5, X2: 105, Y2: 105},     // second person, suppressed
+		{X1: 200, Y1: 200, X2: 300, Y2: 300}, // separate person
+	}
+	scores := []float32{0.9, 0.8, 0.85, 0.7}
+	classIDs := []int{0, 1, 0, 0}
+
+	tests := []struct {
+		name          string
+		agnostic      bool
+		maxDetections int
+		expected      []int
+	}{
+		{name: "Class aware", expected: []int{0, 1, 3}},
+		{name: "Agnostic", agnostic: true, expected: []int{0, 3}},
+		{name: "Max detections", maxDetections: 2, expected: []int{0, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := imgUtils.NMSBoxesBatched(&boxes, &scores, &classIDs, 0.5, 0.5, tt.agnostic, tt.maxDetections)
+			if len(*result) != len(tt.expected) {
+				t.Fatalf("expected indices %v, got %v", tt.expected, *result)
+			}
+			for i, idx := range *result {
+				if idx != tt.expected[i] {
+					t.Errorf("expected indices %v, got %v", tt.expected, *result)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestSubImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	img.Set(30, 40, color.RGBA{R: 255, A: 255})
+
+	sub := SubImage(img, image.Rect(20, 30, 200, 60))
+	if sub.Bounds() != image.Rect(20, 30, 100, 60) {
+		t.Errorf("expected bounds (20,30)-(100,60), got %v", sub.Bounds())
+	}
+	if r, _, _, _ := sub.At(30, 40).RGBA(); r != 0xffff {
+		t.Errorf("expected shared red pixel, got %d", r)
+	}
+
+	// Images without SubImage are copied and rebased to the origin.
+	generic := imageWithoutSubImage{img}
+	sub = SubImage(generic, image.Rect(20, 30, 60, 60))
+	if sub.Bounds() != image.Rect(0, 0, 40, 30) {
+		t.Errorf("expected bounds (0,0)-(40,30), got %v", sub.Bounds())
+	}
+}
+
+type imageWithoutSubImage struct {
+	image.Image
+}
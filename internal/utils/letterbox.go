@@ -0,0 +1,269 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// Interpolation is the filter used to resize images to the model input.
+type Interpolation int
+
+const (
+	// InterpolationBilinear samples the four nearest pixels like OpenCV's
+	// INTER_LINEAR, which Ultralytics uses.
+	InterpolationBilinear Interpolation = iota
+	InterpolationNearest
+	// InterpolationArea averages the pixels covered by each target pixel,
+	// like INTER_AREA when shrinking.
+	InterpolationArea
+	InterpolationLanczos
+)
+
+// PadPosition places the resized image inside the padded input.
+type PadPosition int
+
+const (
+	PadCenter PadPosition = iota
+	PadTopLeft
+)
+
+// LetterboxOptions controls how images are fitted to the square model
+// input. The zero value matches the Ultralytics letterbox.
+type LetterboxOptions struct {
+	Interpolation Interpolation
+	// PadColor fills the padding; nil means gray 114.
+	PadColor color.Color
+	Padding  PadPosition
+	// DisableScaleUp only ever shrinks images, like scaleup=False, so small
+	// images keep their pixels and get more padding.
+	DisableScaleUp bool
+	// Stretch resizes to the input size without keeping the aspect ratio
+	// or padding.
+	Stretch bool
+	// Background, when set, is the colour translucent pixels are
+	// composited onto before resizing. Otherwise alpha is ignored and
+	// transparent pixels keep whatever RGB values they store.
+	Background color.Color
+}
+
+// Transform is the mapping from the original image to the model input:
+// input = original * scale + pad.
+type Transform struct {
+	ScaleX, ScaleY float32
+	PadX, PadY     int
+}
+
+// Restore maps a point of the model input back to the original image.
+func (t *Transform) Restore(x, y float32) (float32, float32) {
+	return (x - float32(t.PadX)) / t.ScaleX, (y - float32(t.PadY)) / t.ScaleY
+}
+
+// Resize returns img resized to width x height with the given filter.
+func Resize(img image.Image, width, height int, interpolation Interpolation) *image.NRGBA {
+	switch interpolation {
+	case InterpolationNearest:
+		return resizeNearest(img, width, height)
+	case InterpolationArea:
+		return imaging.Resize(img, width, height, imaging.Box)
+	case InterpolationLanczos:
+		return imaging.Resize(img, width, height, imaging.Lanczos)
+	default:
+		return resizeBilinear(img, width, height)
+	}
+}
+
+// toNRGBA returns img as an NRGBA image with its origin at 0, 0.
+func toNRGBA(img image.Image) *image.NRGBA {
+	if src, ok := img.(*image.NRGBA); ok && src.Rect.Min == (image.Point{}) {
+		return src
+	}
+	return imaging.Clone(img)
+}
+
+// resizeNearest picks the source pixel under the centre of every target
+// pixel.
+func resizeNearest(img image.Image, width, height int) *image.NRGBA {
+	src := toNRGBA(img)
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	srcWidth, srcHeight := src.Rect.Dx(), src.Rect.Dy()
+	if srcWidth == 0 || srcHeight == 0 {
+		return dst
+	}
+
+	for y := 0; y < height; y++ {
+		row := src.Pix[NearestTap(y, srcHeight, height)*src.Stride:]
+		out := dst.Pix[y*dst.Stride:]
+		for x := 0; x < width; x++ {
+			copy(out[x*4:x*4+4], row[NearestTap(x, srcWidth, width)*4:])
+		}
+	}
+	return dst
+}
+
+// resizeBilinear interpolates between the four nearest source pixels with
+// half-pixel centres. Unlike imaging.Linear the kernel does not widen when
+// shrinking, matching cv2.resize.
+func resizeBilinear(img image.Image, width, height int) *image.NRGBA {
+	src := toNRGBA(img)
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	srcWidth, srcHeight := src.Rect.Dx(), src.Rect.Dy()
+	if srcWidth == 0 || srcHeight == 0 {
+		return dst
+	}
+
+	xs0 := make([]int, width)
+	xs1 := make([]int, width)
+	xWeights := make([]float32, width)
+	BilinearTaps(srcWidth, xs0, xs1, xWeights)
+
+	for y := 0; y < height; y++ {
+		y0, y1, wy := BilinearTap(y, srcHeight, height)
+		row0 := src.Pix[y0*src.Stride:]
+		row1 := src.Pix[y1*src.Stride:]
+		out := dst.Pix[y*dst.Stride:]
+
+		for x := 0; x < width; x++ {
+			i0, i1, wx := xs0[x]*4, xs1[x]*4, xWeights[x]
+			for c := 0; c < 4; c++ {
+				out[x*4+c] = Lerp(row0[i0+c], row0[i1+c], row1[i0+c], row1[i1+c], wx, wy)
+			}
+		}
+	}
+	return dst
+}
+
+// Lerp interpolates bilinearly between the top-left, top-right,
+// bottom-left and bottom-right values and rounds to the nearest byte.
+func Lerp(topLeft, topRight, bottomLeft, bottomRight uint8, wx, wy float32) uint8 {
+	top := float32(topLeft) + float32(wx*(float32(topRight)-float32(topLeft)))
+	bottom := float32(bottomLeft) + float32(wx*(float32(bottomRight)-float32(bottomLeft)))
+	return uint8(top + float32(wy*(bottom-top)) + 0.5)
+}
+
+// BilinearTaps fills, for every target pixel of a resize from srcSize to
+// len(first), the two source pixels and the weight of the second one.
+func BilinearTaps(srcSize int, first, second []int, weights []float32) {
+	for i := range first {
+		first[i], second[i], weights[i] = BilinearTap(i, srcSize, len(first))
+	}
+}
+
+// BilinearTap returns the two source pixels of target pixel dst and the
+// weight of the second one.
+func BilinearTap(dst, srcSize, dstSize int) (int, int, float32) {
+	scale := float32(srcSize) / float32(dstSize)
+	position := max((float32(dst)+0.5)*scale-0.5, 0)
+	i0 := int(position)
+	if i0 >= srcSize-1 {
+		return srcSize - 1, srcSize - 1, 0
+	}
+	return i0, i0 + 1, position - float32(i0)
+}
+
+// NearestTap returns the source pixel under the centre of target pixel
+// dst.
+func NearestTap(dst, srcSize, dstSize int) int {
+	return min(int((float64(dst)+0.5)*float64(srcSize)/float64(dstSize)), srcSize-1)
+}
+
+// PadRGB returns the padding colour as 8-bit RGB.
+func (o *LetterboxOptions) PadRGB() [3]uint8 {
+	if o.PadColor == nil {
+		return [3]uint8{114, 114, 114}
+	}
+	return rgb8(o.PadColor)
+}
+
+// BackgroundRGB returns the background colour as 8-bit RGB and whether
+// alpha compositing is enabled.
+func (o *LetterboxOptions) BackgroundRGB() ([3]uint8, bool) {
+	if o.Background == nil {
+		return [3]uint8{}, false
+	}
+	return rgb8(o.Background), true
+}
+
+func rgb8(c color.Color) [3]uint8 {
+	nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return [3]uint8{nrgba.R, nrgba.G, nrgba.B}
+}
+
+// Composite blends straight-alpha RGBA pixels over background in place and
+// makes them opaque.
+func Composite(pixels []uint8, background [3]uint8) {
+	for i := 0; i+3 < len(pixels); i += 4 {
+		a := uint32(pixels[i+3])
+		if a == 0xff {
+			continue
+		}
+		for c := range 3 {
+			pixels[i+c] = uint8((uint32(pixels[i+c])*a + uint32(background[c])*(0xff-a) + 0x7f) / 0xff)
+		}
+		pixels[i+3] = 0xff
+	}
+}
+
+// flatten returns a copy of img composited over background.
+func flatten(img image.Image, background [3]uint8) *image.NRGBA {
+	flat := imaging.Clone(img)
+	for y := range flat.Rect.Dy() {
+		Composite(flat.Pix[y*flat.Stride:y*flat.Stride+flat.Rect.Dx()*4], background)
+	}
+	return flat
+}
+
+// Geometry returns the transform Letterbox applies to a width x height
+// image and the size of the resized image inside the input.
+func (i *ImageUtils) Geometry(width, height, inputSize int, options *LetterboxOptions) (Transform, image.Point) {
+	if options.Stretch {
+		transform := Transform{
+			ScaleX: float32(inputSize) / float32(width),
+			ScaleY: float32(inputSize) / float32(height),
+		}
+		return transform, image.Pt(inputSize, inputSize)
+	}
+
+	scale := math.Min(float64(inputSize)/float64(width), float64(inputSize)/float64(height))
+	if options.DisableScaleUp {
+		scale = math.Min(scale, 1)
+	}
+	newWidth := int(math.Round(float64(width) * scale))
+	newHeight := int(math.Round(float64(height) * scale))
+
+	transform := Transform{ScaleX: float32(scale), ScaleY: float32(scale)}
+	if options.Padding == PadCenter {
+		transform.PadX = (inputSize - newWidth) / 2
+		transform.PadY = (inputSize - newHeight) / 2
+	}
+	return transform, image.Pt(newWidth, newHeight)
+}
+
+// Letterbox fits img into an inputSize square and returns the transform
+// from img to the result.
+func (i *ImageUtils) Letterbox(img image.Image, inputSize int, options *LetterboxOptions) (image.Image, Transform) {
+	if background, ok := options.BackgroundRGB(); ok {
+		img = flatten(img, background)
+	}
+	transform, size := i.Geometry(img.Bounds().Dx(), img.Bounds().Dy(), inputSize, options)
+	resizedImg := resizeTo(img, size.X, size.Y, options.Interpolation)
+	if options.Stretch {
+		return resizedImg, transform
+	}
+
+	pad := options.PadRGB()
+	paddedImg := imaging.New(inputSize, inputSize, color.NRGBA{R: pad[0], G: pad[1], B: pad[2], A: 255})
+	paddedImg = imaging.Paste(paddedImg, resizedImg, image.Pt(transform.PadX, transform.PadY))
+
+	return paddedImg, transform
+}
+
+// resizeTo resizes img unless it already has the target size.
+func resizeTo(img image.Image, width, height int, interpolation Interpolation) image.Image {
+	if img.Bounds().Dx() == width && img.Bounds().Dy() == height {
+		return img
+	}
+	return Resize(img, width, height, interpolation)
+}
@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestLetterboxOptions(t *testing.T) {
+	imgUtils := ImageUtils{}
+	red := color.NRGBA{R: 255, A: 255}
+
+	tests := []struct {
+		name      string
+		size      image.Point
+		options   LetterboxOptions
+		expected  Transform
+		padAt     image.Point
+		padColor  color.NRGBA
+		imageAt   image.Point
+		imageSize int
+	}{
+		{
+			name:     "Centre",
+			size:     image.Pt(200, 100),
+			expected: Transform{ScaleX: 1.5, ScaleY: 1.5, PadX: 0, PadY: 75},
+			padAt:    image.Pt(150, 10),
+			padColor: color.NRGBA{R: 114, G: 114, B: 114, A: 255},
+			imageAt:  image.Pt(150, 150),
+		},
+		{
+			name:     "Top-left with pad colour",
+			size:     image.Pt(200, 100),
+			options:  LetterboxOptions{Padding: PadTopLeft, PadColor: color.Black},
+			expected: Transform{ScaleX: 1.5, ScaleY: 1.5},
+			padAt:    image.Pt(150, 200),
+			padColor: color.NRGBA{A: 255},
+			imageAt:  image.Pt(150, 10),
+		},
+		{
+			name:     "No scale up",
+			size:     image.Pt(100, 50),
+			options:  LetterboxOptions{DisableScaleUp: true},
+			expected: Transform{ScaleX: 1, ScaleY: 1, PadX: 100, PadY: 125},
+			padAt:    image.Pt(50, 150),
+			padColor: color.NRGBA{R: 114, G: 114, B: 114, A: 255},
+			imageAt:  image.Pt(150, 150),
+		},
+		{
+			name:     "No scale up still shrinks",
+			size:     image.Pt(600, 300),
+			options:  LetterboxOptions{DisableScaleUp: true},
+			expected: Transform{ScaleX: 0.5, ScaleY: 0.5, PadX: 0, PadY: 75},
+			padAt:    image.Pt(150, 10),
+			padColor: color.NRGBA{R: 114, G: 114, B: 114, A: 255},
+			imageAt:  image.Pt(150, 150),
+		},
+		{
+			name:     "Stretch",
+			size:     image.Pt(200, 100),
+			options:  LetterboxOptions{Stretch: true},
+			expected: Transform{ScaleX: 1.5, ScaleY: 3},
+			imageAt:  image.Pt(150, 10),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewNRGBA(image.Rectangle{Max: tt.size})
+			for i := 0; i < len(img.Pix); i += 4 {
+				copy(img.Pix[i:], []uint8{255, 0, 0, 255})
+			}
+
+			result, transform := imgUtils.Letterbox(img, 300, &tt.options)
+			if transform != tt.expected {
+				t.Errorf("expected transform %+v, got %+v", tt.expected, transform)
+			}
+			if result.Bounds().Size() != image.Pt(300, 300) {
+				t.Fatalf("expected a 300x300 result, got %v", result.Bounds())
+			}
+			if tt.padColor != (color.NRGBA{}) {
+				if c := color.NRGBAModel.Convert(result.At(tt.padAt.X, tt.padAt.Y)); c != tt.padColor {
+					t.Errorf("expected padding %v at %v, got %v", tt.padColor, tt.padAt, c)
+				}
+			}
+			if c := color.NRGBAModel.Convert(result.At(tt.imageAt.X, tt.imageAt.Y)); c != red {
+				t.Errorf("expected image pixel at %v, got %v", tt.imageAt, c)
+			}
+		})
+	}
+}
+
+func TestTransformRestore(t *testing.T) {
+	transform := Transform{ScaleX: 2, ScaleY: 0.5, PadX: 10, PadY: 4}
+	x, y := transform.Restore(30, 14)
+	if x != 10 || y != 20 {
+		t.Errorf("expected (10, 20), got (%f, %f)", x, y)
+	}
+}
+
+func TestResizeBilinear(t *testing.T) {
+	// cv2.resize(np.array([[0, 100]], np.uint8), (4, 1)) gives
+	// [0, 25, 75, 100].
+	img := image.NewGray(image.Rect(0, 0, 2, 1))
+	img.Pix = []uint8{0, 100}
+
+	result := Resize(img, 4, 1, InterpolationBilinear)
+	for x, expected := range []uint8{0, 25, 75, 100} {
+		if got := result.NRGBAAt(x, 0).R; got != expected {
+			t.Errorf("expected %d at %d, got %d", expected, x, got)
+		}
+	}
+
+	// Shrinking 4 -> 2 samples between pixel pairs without widening the
+	// kernel: cv2 gives [50, 150] for [0, 100, 100, 200].
+	img = image.NewGray(image.Rect(0, 0, 4, 1))
+	img.Pix = []uint8{0, 100, 100, 200}
+	result = Resize(img, 2, 1, InterpolationBilinear)
+	for x, expected := range []uint8{50, 150} {
+		if got := result.NRGBAAt(x, 0).R; got != expected {
+			t.Errorf("expected %d at %d, got %d", expected, x, got)
+		}
+	}
+}
+
+func TestResizeInterpolations(t *testing.T) {
+	img := image.NewRGBA(image.Rect(10, 10, 50, 30))
+	for _, interpolation := range []Interpolation{InterpolationBilinear, InterpolationNearest, InterpolationArea, InterpolationLanczos} {
+		result := Resize(img, 20, 10, interpolation)
+		if result.Bounds() != image.Rect(0, 0, 20, 10) {
+			t.Errorf("interpolation %d: expected 20x10 at the origin, got %v", interpolation, result.Bounds())
+		}
+	}
+}
+
+func TestLetterboxBackground(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 0})
+	img.SetNRGBA(1, 0, color.NRGBA{R: 255, A: 128})
+	img.SetNRGBA(2, 0, color.NRGBA{R: 255, A: 255})
+
+	options := LetterboxOptions{Stretch: true, Interpolation: InterpolationNearest, Background: color.White}
+	result, _ := (&ImageUtils{}).Letterbox(img, 3, &options)
+
+	expected := []color.NRGBA{
+		{R: 255, G: 255, B: 255, A: 255},
+		{R: 255, G: 127, B: 127, A: 255},
+		{R: 255, A: 255},
+	}
+	for x, want := range expected {
+		if got := color.NRGBAModel.Convert(result.At(x, 0)); got != want {
+			t.Errorf("expected %v at %d, got %v", want, x, got)
+		}
+	}
+	if img.NRGBAAt(0, 0).R != 255 || img.NRGBAAt(0, 0).A != 0 {
+		t.Errorf("expected the source image to be left untouched, got %v", img.NRGBAAt(0, 0))
+	}
+}
@@ -0,0 +1,199 @@
+package utils
+
+import (
+	"math"
+	"slices"
+)
+
+// Box is an axis-aligned box in float pixel coordinates.
+type Box struct {
+	X1, Y1, X2, Y2 float32
+}
+
+func (b *Box) Area() float32 {
+	return max(0, b.X2-b.X1) * max(0, b.Y2-b.Y1)
+}
+
+// maxGridCells bounds the spatial grid so huge boxes or sparse candidates
+// never make building it more expensive than the pairwise scan it replaces.
+const maxGridCells = 64
+
+// NMS is a greedy suppressor over float boxes. Candidates are sorted once
+// by score and bucketed into a uniform grid, so every kept box is only
+// compared against candidates in the cells it covers. The buffers are
+// reused between calls; an NMS value must not be used concurrently.
+type NMS struct {
+	order      []int
+	rank       []int
+	suppressed []bool
+	areas      []float32
+	keep       []int
+
+	// Grid cells in CSR form: the candidates of cell c are
+	// cellItems[cellStart[c]:cellStart[c+1]], in rank order.
+	cellStart []int
+	cellItems []int
+	cellFill  []int
+	cellSpan  []int
+}
+
+// Run returns the indices of the boxes kept by greedy NMS, ordered by
+// descending score. Boxes scoring at or below scoreThreshold are ignored
+// and a box is suppressed when its IoU with a kept box reaches
+// iouThreshold. When classIDs is not nil only boxes of the same class
+// suppress each other. The returned slice is owned by n and is only valid
+// until the next call.
+func (n *NMS) Run(boxes []Box, scores []float32, classIDs []int,
+	scoreThreshold, iouThreshold float32, maxDetections int,
+) []int {
+	n.order = n.order[:0]
+	for idx, score := range scores {
+		if score > scoreThreshold {
+			n.order = append(n.order, idx)
+		}
+	}
+	// Ties keep index order, matching the original selection order.
+	slices.SortFunc(n.order, func(a, b int) int {
+		switch {
+		case scores[a] > scores[b]:
+			return -1
+		case scores[a] < scores[b]:
+			return 1
+		}
+		return a - b
+	})
+
+	count := len(n.order)
+	n.rank = resize(n.rank, len(boxes))
+	n.suppressed = resize(n.suppressed, count)
+	n.areas = resize(n.areas, count)
+	for r, idx := range n.order {
+		n.rank[idx] = r
+		n.suppressed[r] = false
+		n.areas[r] = boxes[idx].Area()
+	}
+
+	grid := n.buildGrid(boxes)
+
+	n.keep = n.keep[:0]
+	for r, idx := range n.order {
+		if n.suppressed[r] {
+			continue
+		}
+		n.keep = append(n.keep, idx)
+		if maxDetections > 0 && len(n.keep) >= maxDetections {
+			break
+		}
+
+		current := &boxes[idx]
+		x1, y1, x2, y2 := grid.cells(current)
+		for cy := y1; cy <= y2; cy++ {
+			for cx := x1; cx <= x2; cx++ {
+				c := cy*grid.columns + cx
+				for _, other := range n.cellItems[n.cellStart[c]:n.cellStart[c+1]] {
+					otherRank := n.rank[other]
+					if otherRank <= r || n.suppressed[otherRank] {
+						continue
+					}
+					if classIDs != nil && classIDs[other] != classIDs[idx] {
+						continue
+					}
+					if iou(current, &boxes[other], n.areas[r], n.areas[otherRank]) >= iouThreshold {
+						n.suppressed[otherRank] = true
+					}
+				}
+			}
+		}
+	}
+	return n.keep
+}
+
+type nmsGrid struct {
+	minX, minY    float32
+	cellSize      float32
+	columns, rows int
+}
+
+func (g *nmsGrid) cells(box *Box) (int, int, int, int) {
+	clamp := func(value float32, limit int) int {
+		return min(max(int(value/g.cellSize), 0), limit-1)
+	}
+	return clamp(box.X1-g.minX, g.columns), clamp(box.Y1-g.minY, g.rows),
+		clamp(box.X2-g.minX, g.columns), clamp(box.Y2-g.minY, g.rows)
+}
+
+// buildGrid sizes the cells to the mean candidate size and fills the CSR
+// cell lists. A box is listed in every cell it overlaps.
+func (n *NMS) buildGrid(boxes []Box) nmsGrid {
+	grid := nmsGrid{cellSize: 1, columns: 1, rows: 1}
+	if len(n.order) > 0 {
+		minX, minY := float32(math.MaxFloat32), float32(math.MaxFloat32)
+		maxX, maxY := float32(-math.MaxFloat32), float32(-math.MaxFloat32)
+		var sumSize float32
+		for _, idx := range n.order {
+			box := &boxes[idx]
+			minX, minY = min(minX, box.X1), min(minY, box.Y1)
+			maxX, maxY = max(maxX, box.X2), max(maxY, box.Y2)
+			sumSize += max(box.X2-box.X1, box.Y2-box.Y1)
+		}
+
+		extent := max(maxX-minX, maxY-minY)
+		cellSize := max(sumSize/float32(len(n.order)), extent/maxGridCells, 1)
+		grid = nmsGrid{
+			minX:     minX,
+			minY:     minY,
+			cellSize: cellSize,
+			columns:  int((maxX-minX)/cellSize) + 1,
+			rows:     int((maxY-minY)/cellSize) + 1,
+		}
+	}
+
+	cellCount := grid.columns * grid.rows
+	n.cellStart = resize(n.cellStart, cellCount+1)
+	clear(n.cellStart)
+	n.cellSpan = resize(n.cellSpan, 4*len(n.order))
+	for r, idx := range n.order {
+		x1, y1, x2, y2 := grid.cells(&boxes[idx])
+		n.cellSpan[4*r], n.cellSpan[4*r+1], n.cellSpan[4*r+2], n.cellSpan[4*r+3] = x1, y1, x2, y2
+		for cy := y1; cy <= y2; cy++ {
+			for cx := x1; cx <= x2; cx++ {
+				n.cellStart[cy*grid.columns+cx+1]++
+			}
+		}
+	}
+	for c := 1; c <= cellCount; c++ {
+		n.cellStart[c] += n.cellStart[c-1]
+	}
+
+	n.cellItems = resize(n.cellItems, n.cellStart[cellCount])
+	n.cellFill = resize(n.cellFill, cellCount)
+	copy(n.cellFill, n.cellStart)
+	for r, idx := range n.order {
+		x1, y1, x2, y2 := n.cellSpan[4*r], n.cellSpan[4*r+1], n.cellSpan[4*r+2], n.cellSpan[4*r+3]
+		for cy := y1; cy <= y2; cy++ {
+			for cx := x1; cx <= x2; cx++ {
+				c := cy*grid.columns + cx
+				n.cellItems[n.cellFill[c]] = idx
+				n.cellFill[c]++
+			}
+		}
+	}
+	return grid
+}
+
+func iou(a, b *Box, areaA, areaB float32) float32 {
+	interWidth := min(a.X2, b.X2) - max(a.X1, b.X1)
+	interHeight := min(a.Y2, b.Y2) - max(a.Y1, b.Y1)
+	if interWidth <= 0 || interHeight <= 0 {
+		return 0
+	}
+	interArea := interWidth * interHeight
+	return interArea / (areaA + areaB - interArea)
+}
+
+func resize[T any](buffer []T, size int) []T {
+	if cap(buffer) < size {
+		return make([]T, size)
+	}
+	return buffer[:size]
+}
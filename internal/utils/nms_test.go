@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"image"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// naiveNMSBoxes is the original quadratic NMS, kept as a reference for
+// correctness and benchmarks.
+func naiveNMSBoxes(boxes []image.Rectangle, scores []float32, scoreThreshold, nmsThreshold float32) []int {
+	iu := ImageUtils{}
+	filteredBoxes := []image.Rectangle{}
+	filteredScores := []float32{}
+	indices := []int{}
+
+	for idx, score := range scores {
+		if score > scoreThreshold {
+			filteredBoxes = append(filteredBoxes, boxes[idx])
+			filteredScores = append(filteredScores, score)
+			indices = append(indices, idx)
+		}
+	}
+
+	selectedIndices := []int{}
+	for len(indices) > 0 {
+		maxIdx := 0
+		for i, score := range filteredScores {
+			if score > filteredScores[maxIdx] {
+				maxIdx = i
+			}
+		}
+
+		selectedIndices = append(selectedIndices, indices[maxIdx])
+		currentBox := filteredBoxes[maxIdx]
+
+		newIndices := []int{}
+		newBoxes := []image.Rectangle{}
+		newScores := []float32{}
+		for i, idx := range indices {
+			if i != maxIdx && iu.Iou(currentBox, filteredBoxes[i]) < float64(nmsThreshold) {
+				newIndices = append(newIndices, idx)
+				newBoxes = append(newBoxes, filteredBoxes[i])
+				newScores = append(newScores, filteredScores[i])
+			}
+		}
+		indices = newIndices
+		filteredBoxes = newBoxes
+		filteredScores = newScores
+	}
+	return selectedIndices
+}
+
+// randomCandidates mimics raw detector output: jittered clusters of boxes
+// around a set of objects on a 640x640 input, with a few classes.
+func randomCandidates(count int, seed int64) ([]image.Rectangle, []Box, []float32, []int) {
+	rng := rand.New(rand.NewSource(seed))
+	rects := make([]image.Rectangle, count)
+	boxes := make([]Box, count)
+	scores := make([]float32, count)
+	classIDs := make([]int, count)
+
+	const objects = 60
+	for i := 0; i < count; i++ {
+		object := rng.Intn(objects)
+		objectRng := rand.New(rand.NewSource(seed + int64(object)))
+		cx, cy := objectRng.Intn(600)+20, objectRng.Intn(600)+20
+		w, h := objectRng.Intn(120)+8, objectRng.Intn(120)+8
+
+		x1 := cx - w/2 + rng.Intn(9) - 4
+		y1 := cy - h/2 + rng.Intn(9) - 4
+		x2 := x1 + w + rng.Intn(9) - 4
+		y2 := y1 + h + rng.Intn(9) - 4
+
+		rects[i] = image.Rect(x1, y1, x2, y2)
+		boxes[i] = Box{X1: float32(x1), Y1: float32(y1), X2: float32(x2), Y2: float32(y2)}
+		scores[i] = rng.Float32()
+		classIDs[i] = object % 3
+	}
+	return rects, boxes, scores, classIDs
+}
+
+func TestNMSMatchesReference(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		rects, boxes, scores, _ := randomCandidates(2000, seed)
+
+		expected := naiveNMSBoxes(rects, scores, 0.25, 0.45)
+
+		nms := NMS{}
+		result := nms.Run(boxes, scores, nil, 0.25, 0.45, 0)
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("seed %d: expected %d kept boxes %v, got %d %v", seed, len(expected), expected, len(result), result)
+		}
+	}
+}
+
+func TestNMSClassAware(t *testing.T) {
+	_, boxes, scores, classIDs := randomCandidates(2000, 42)
+
+	nms := NMS{}
+	result := append([]int{}, nms.Run(boxes, scores, classIDs, 0.25, 0.45, 0)...)
+
+	// Per-class agnostic runs must keep exactly the same boxes.
+	expected := map[int]bool{}
+	for class := 0; class < 3; class++ {
+		classScores := make([]float32, len(scores))
+		for i := range scores {
+			if classIDs[i] == class {
+				classScores[i] = scores[i]
+			}
+		}
+		for _, idx := range nms.Run(boxes, classScores, nil, 0.25, 0.45, 0) {
+			expected[idx] = true
+		}
+	}
+
+	if len(result) != len(expected) {
+		t.Fatalf("expected %d kept boxes, got %d", len(expected), len(result))
+	}
+	for _, idx := range result {
+		if !expected[idx] {
+			t.Errorf("unexpected kept box %d", idx)
+		}
+	}
+}
+
+func TestNMSMaxDetections(t *testing.T) {
+	_, boxes, scores, _ := randomCandidates(500, 7)
+
+	nms := NMS{}
+	all := append([]int{}, nms.Run(boxes, scores, nil, 0.25, 0.45, 0)...)
+	capped := nms.Run(boxes, scores, nil, 0.25, 0.45, 5)
+
+	if !reflect.DeepEqual(capped, all[:5]) {
+		t.Errorf("expected %v, got %v", all[:5], capped)
+	}
+}
+
+func TestNMSFloatPrecision(t *testing.T) {
+	// Truncating to integers would make these small boxes identical.
+	boxes := []Box{
+		{X1: 10.1, Y1: 10.1, X2: 12.9, Y2: 12.9},
+		{X1: 10.9, Y1: 10.9, X2: 12.1, Y2: 12.1},
+	}
+	scores := []float32{0.9, 0.8}
+
+	nms := NMS{}
+	result := nms.Run(boxes, scores, nil, 0.5, 0.5, 0)
+	if !reflect.DeepEqual(result, []int{0, 1}) {
+		t.Errorf("expected both boxes to be kept, got %v", result)
+	}
+}
+
+const benchmarkCandidates = 8400
+
+func BenchmarkNaiveNMSBoxes(b *testing.B) {
+	rects, _, scores, _ := randomCandidates(benchmarkCandidates, 1)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		naiveNMSBoxes(rects, scores, 0.25, 0.45)
+	}
+}
+
+func BenchmarkNMSBoxes(b *testing.B) {
+	rects, _, scores, _ := randomCandidates(benchmarkCandidates, 1)
+	imgUtils := ImageUtils{}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		imgUtils.NMSBoxes(&rects, &scores, 0.25, 0.45)
+	}
+}
+
+func BenchmarkNMS(b *testing.B) {
+	_, boxes, scores, classIDs := randomCandidates(benchmarkCandidates, 1)
+	nms := NMS{}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		nms.Run(boxes, scores, classIDs, 0.25, 0.45, 300)
+	}
+}
@@ -0,0 +1,150 @@
+package utils
+
+type Point struct {
+	X, Y float32
+}
+
+// Polygon is a simple polygon given by its vertices in order. The closing
+// edge from the last vertex back to the first is implicit.
+type Polygon []Point
+
+// Contains reports whether pt lies inside the polygon, using the even-odd
+// rule.
+func (p Polygon) Contains(pt Point) bool {
+	inside := false
+	for i, j := 0, len(p)-1; i < len(p); j, i = i, i+1 {
+		a, b := p[i], p[j]
+		if (a.Y > pt.Y) != (b.Y > pt.Y) &&
+			pt.X < (b.X-a.X)*(pt.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Area returns the unsigned area of the polygon.
+func (p Polygon) Area() float32 {
+	var area float32
+	for i, j := 0, len(p)-1; i < len(p); j, i = i, i+1 {
+		area += p[j].X*p[i].Y - p[i].X*p[j].Y
+	}
+	return max(area, -area) / 2
+}
+
+// IntersectionArea returns the area of the polygon that lies inside box.
+// The polygon is clipped against each edge of the box
+// (Sutherland-Hodgman), which is exact for any simple polygon because the
+// clip region is convex.
+func (p Polygon) IntersectionArea(box Box) float32 {
+	clipped := p
+	edges := []struct {
+		inside    func(Point) bool
+		intersect func(a, b Point) Point
+	}{
+		{
+			func(pt Point) bool { return pt.X >= box.X1 },
+			func(a, b Point) Point { return lerpX(a, b, box.X1) },
+		},
+		{
+			func(pt Point) bool { return pt.X <= box.X2 },
+			func(a, b Point) Point { return lerpX(a, b, box.X2) },
+		},
+		{
+			func(pt Point) bool { return pt.Y >= box.Y1 },
+			func(a, b Point) Point { return lerpY(a, b, box.Y1) },
+		},
+		{
+			func(pt Point) bool { return pt.Y <= box.Y2 },
+			func(a, b Point) Point { return lerpY(a, b, box.Y2) },
+		},
+	}
+
+	for _, edge := range edges {
+		if len(clipped) == 0 {
+			return 0
+		}
+		input := clipped
+		clipped = make(Polygon, 0, len(input)+4)
+		previous := input[len(input)-1]
+		for _, current := range input {
+			switch {
+			case edge.inside(current) && edge.inside(previous):
+				clipped = append(clipped, current)
+			case edge.inside(current):
+				clipped = append(clipped, edge.intersect(previous, current), current)
+			case edge.inside(previous):
+				clipped = append(clipped, edge.intersect(previous, current))
+			}
+			previous = current
+		}
+	}
+	return clipped.Area()
+}
+
+func lerpX(a, b Point, x float32) Point {
+	t := (x - a.X) / (b.X - a.X)
+	return Point{X: x, Y: a.Y + t*(b.Y-a.Y)}
+}
+
+func lerpY(a, b Point, y float32) Point {
+	t := (y - a.Y) / (b.Y - a.Y)
+	return Point{X: a.X + t*(b.X-a.X), Y: y}
+}
+
+type ExclusionMode int
+
+const (
+	// ExcludeByCenter drops detections whose box centre is inside the mask.
+	ExcludeByCenter ExclusionMode = iota
+	// ExcludeByOverlap drops detections with at least MinOverlap of their
+	// box area inside the mask; a zero MinOverlap means any overlap.
+	ExcludeByOverlap
+)
+
+// ExclusionMask is an image region where detections are ignored, such as
+// static signage seen by a fixed camera.
+type ExclusionMask struct {
+	Polygon    Polygon
+	Mode       ExclusionMode
+	MinOverlap float32
+}
+
+func (m *ExclusionMask) Excludes(box *BoundingBox) bool {
+	if m.Mode == ExcludeByOverlap {
+		area := box.Area()
+		if area <= 0 {
+			return false
+		}
+		overlap := m.Polygon.IntersectionArea(Box{X1: box.X1, Y1: box.Y1, X2: box.X2, Y2: box.Y2})
+		return overlap > 0 && overlap/area >= m.MinOverlap
+	}
+
+	center := Point{X: (box.X1 + box.X2) / 2, Y: (box.Y1 + box.Y2) / 2}
+	return m.Polygon.Contains(center)
+}
+
+// FilterExcluded drops the boxes excluded by any of the masks, reusing the
+// backing array of boxes.
+func FilterExcluded(boxes []BoundingBox, masks []ExclusionMask) []BoundingBox {
+	if len(masks) == 0 {
+		return boxes
+	}
+
+	results := boxes[:0]
+	for _, box := range boxes {
+		if !Excluded(&box, masks) {
+			results = append(results, box)
+		}
+	}
+	return results
+}
+
+// Excluded reports whether any of the masks excludes box.
+func Excluded(box *BoundingBox, masks []ExclusionMask) bool {
+	for i := range masks {
+		if masks[i].Excludes(box) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+// lShape is a concave polygon covering (0,0)-(10,10) minus (5,5)-(10,10).
+var lShape = Polygon{{0, 0}, {10, 0}, {10, 5}, {5, 5}, {5, 10}, {0, 10}}
+
+func TestPolygonContains(t *testing.T) {
+	tests := []struct {
+		point    Point
+		expected bool
+	}{
+		{Point{2, 2}, true},
+		{Point{8, 2}, true},
+		{Point{2, 8}, true},
+		{Point{8, 8}, false},
+		{Point{-1, 5}, false},
+		{Point{11, 1}, false},
+	}
+
+	for _, tt := range tests {
+		if result := lShape.Contains(tt.point); result != tt.expected {
+			t.Errorf("Contains(%v): expected %v, got %v", tt.point, tt.expected, result)
+		}
+	}
+}
+
+func TestPolygonArea(t *testing.T) {
+	if area := lShape.Area(); area != 75 {
+		t.Errorf("expected area 75, got %f", area)
+	}
+
+	// Clockwise order gives the same unsigned area.
+	reversed := Polygon{{0, 10}, {5, 10}, {5, 5}, {10, 5}, {10, 0}, {0, 0}}
+	if area := reversed.Area(); area != 75 {
+		t.Errorf("expected area 75, got %f", area)
+	}
+}
+
+func TestPolygonIntersectionArea(t *testing.T) {
+	tests := []struct {
+		name     string
+		box      Box
+		expected float32
+	}{
+		{name: "Fully inside", box: Box{1, 1, 4, 4}, expected: 9},
+		{name: "Across the notch", box: Box{4, 4, 8, 8}, expected: 7},
+		{name: "In the notch", box: Box{6, 6, 9, 9}, expected: 0},
+		{name: "Outside", box: Box{20, 20, 30, 30}, expected: 0},
+		{name: "Covering", box: Box{-5, -5, 15, 15}, expected: 75},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			area := lShape.IntersectionArea(tt.box)
+			if math.Abs(float64(area-tt.expected)) > 1e-4 {
+				t.Errorf("expected %f, got %f", tt.expected, area)
+			}
+		})
+	}
+}
+
+func TestFilterExcluded(t *testing.T) {
+	sign := Polygon{{100, 0}, {200, 0}, {200, 100}, {100, 100}}
+
+	boxes := []BoundingBox{
+		{Label: "centre inside", X1: 120, Y1: 20, X2: 180, Y2: 80},
+		{Label: "partly inside", X1: 60, Y1: 20, X2: 120, Y2: 80},
+		{Label: "outside", X1: 300, Y1: 300, X2: 350, Y2: 350},
+	}
+
+	labels := func(boxes []BoundingBox) []string {
+		result := []string{}
+		for _, box := range boxes {
+			result = append(result, box.Label)
+		}
+		return result
+	}
+
+	byCenter := FilterExcluded(append([]BoundingBox{}, boxes...), []ExclusionMask{{Polygon: sign}})
+	if got := labels(byCenter); len(got) != 2 || got[0] != "partly inside" || got[1] != "outside" {
+		t.Errorf("centre mode: unexpected result %v", got)
+	}
+
+	// The partly inside box has a third of its area in the mask.
+	byOverlap := FilterExcluded(append([]BoundingBox{}, boxes...), []ExclusionMask{
+		{Polygon: sign, Mode: ExcludeByOverlap, MinOverlap: 0.3},
+	})
+	if got := labels(byOverlap); len(got) != 1 || got[0] != "outside" {
+		t.Errorf("overlap mode: unexpected result %v", got)
+	}
+
+	byOverlap = FilterExcluded(append([]BoundingBox{}, boxes...), []ExclusionMask{
+		{Polygon: sign, Mode: ExcludeByOverlap, MinOverlap: 0.5},
+	})
+	if got := labels(byOverlap); len(got) != 2 || got[0] != "partly inside" {
+		t.Errorf("overlap mode: unexpected result %v", got)
+	}
+	// A zero MinOverlap drops any box touching the mask and keeps the rest.
+	byOverlap = FilterExcluded(append([]BoundingBox{}, boxes...), []ExclusionMask{
+		{Polygon: sign, Mode: ExcludeByOverlap},
+	})
+	if got := labels(byOverlap); len(got) != 1 || got[0] != "outside" {
+		t.Errorf("overlap mode without minimum: unexpected result %v", got)
+	}
+}
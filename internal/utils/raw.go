@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// PixelFormat is the memory layout of a raw frame.
+type PixelFormat int
+
+const (
+	// FormatRGB24 packs three bytes per pixel in R, G, B order.
+	FormatRGB24 PixelFormat = iota
+	// FormatBGR24 packs three bytes per pixel in B, G, R order, as OpenCV
+	// and many capture APIs do.
+	FormatBGR24
+	// FormatRGBA packs four bytes per pixel with straight alpha.
+	FormatRGBA
+	// FormatNV12 is a full-resolution Y plane followed by one plane of
+	// interleaved U and V samples at half resolution in both directions.
+	FormatNV12
+	// FormatI420 is a full-resolution Y plane followed by U and V planes
+	// at half resolution in both directions (YUV420p).
+	FormatI420
+)
+
+func (f PixelFormat) String() string {
+	switch f {
+	case FormatRGB24:
+		return "RGB24"
+	case FormatBGR24:
+		return "BGR24"
+	case FormatRGBA:
+		return "RGBA"
+	case FormatNV12:
+		return "NV12"
+	case FormatI420:
+		return "I420"
+	default:
+		return fmt.Sprintf("PixelFormat(%d)", int(f))
+	}
+}
+
+// bytesPerPixel returns the size of a pixel of the first plane.
+func (f PixelFormat) bytesPerPixel() int {
+	switch f {
+	case FormatRGB24, FormatBGR24:
+		return 3
+	case FormatRGBA:
+		return 4
+	default:
+		return 1
+	}
+}
+
+// RawImage is a frame in a raw pixel buffer, used in place without
+// copying. Planes are stored one after the other: the luma plane has
+// Stride bytes per row, NV12 chroma rows also have Stride bytes and I420
+// chroma rows Stride/2, rounded up. YUV samples are full-range BT.601, the
+// same conversion as image.YCbCr.
+type RawImage struct {
+	Pix    []byte
+	Stride int
+	Format PixelFormat
+	Rect   image.Rectangle
+
+	// chroma is the offset of the first chroma plane and chromaV the
+	// offset of the I420 V plane.
+	chroma, chromaV int
+	chromaStride    int
+}
+
+// NewRawImage wraps buf as a width x height frame and checks that it is
+// large enough for the format.
+func NewRawImage(buf []byte, width, height, stride int, format PixelFormat) (*RawImage, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid frame size %dx%d", width, height)
+	}
+	if format < FormatRGB24 || format > FormatI420 {
+		return nil, fmt.Errorf("unsupported pixel format %v", format)
+	}
+	if stride < width*format.bytesPerPixel() {
+		return nil, fmt.Errorf("stride %d is too small for %d %v pixels", stride, width, format)
+	}
+
+	img := &RawImage{Pix: buf, Stride: stride, Format: format, Rect: image.Rect(0, 0, width, height)}
+	// The last row of each plane only needs its pixels.
+	size := (height-1)*stride + width*format.bytesPerPixel()
+	chromaWidth, chromaHeight := (width+1)/2, (height+1)/2
+	switch format {
+	case FormatNV12:
+		img.chroma, img.chromaStride = height*stride, stride
+		size = img.chroma + (chromaHeight-1)*stride + chromaWidth*2
+	case FormatI420:
+		img.chromaStride = (stride + 1) / 2
+		img.chroma = height * stride
+		img.chromaV = img.chroma + chromaHeight*img.chromaStride
+		size = img.chromaV + (chromaHeight-1)*img.chromaStride + chromaWidth
+	}
+	if len(buf) < size {
+		return nil, fmt.Errorf("buffer of %d bytes is too small for a %dx%d %v frame", len(buf), width, height, format)
+	}
+	return img, nil
+}
+
+func (r *RawImage) ColorModel() color.Model {
+	switch r.Format {
+	case FormatNV12, FormatI420:
+		return color.YCbCrModel
+	default:
+		return color.NRGBAModel
+	}
+}
+
+func (r *RawImage) Bounds() image.Rectangle {
+	return r.Rect
+}
+
+func (r *RawImage) At(x, y int) color.Color {
+	if !(image.Point{X: x, Y: y}.In(r.Rect)) {
+		return color.NRGBA{}
+	}
+
+	i := y*r.Stride + x*r.Format.bytesPerPixel()
+	switch r.Format {
+	case FormatRGB24:
+		return color.NRGBA{R: r.Pix[i], G: r.Pix[i+1], B: r.Pix[i+2], A: 0xff}
+	case FormatBGR24:
+		return color.NRGBA{R: r.Pix[i+2], G: r.Pix[i+1], B: r.Pix[i], A: 0xff}
+	case FormatRGBA:
+		return color.NRGBA{R: r.Pix[i], G: r.Pix[i+1], B: r.Pix[i+2], A: r.Pix[i+3]}
+	default:
+		cb, cr := r.chromaAt(x, y)
+		return color.YCbCr{Y: r.Pix[i], Cb: cb, Cr: cr}
+	}
+}
+
+// chromaAt returns the U and V samples of pixel x, y of a YUV frame.
+func (r *RawImage) chromaAt(x, y int) (uint8, uint8) {
+	if r.Format == FormatNV12 {
+		i := r.chroma + y/2*r.chromaStride + x/2*2
+		return r.Pix[i], r.Pix[i+1]
+	}
+	i := y/2*r.chromaStride + x/2
+	return r.Pix[r.chroma+i], r.Pix[r.chromaV+i]
+}
+
+// SubImage returns the part of the frame inside rect, sharing its pixels.
+func (r *RawImage) SubImage(rect image.Rectangle) image.Image {
+	sub := *r
+	sub.Rect = rect.Intersect(r.Rect)
+	return &sub
+}
+
+// RowRGBA returns row y of the image, counted from its top, as 8-bit
+// straight-alpha RGBA. RGBA rows are returned in place; other formats are
+// converted into buf, which must hold four bytes per pixel.
+func (r *RawImage) RowRGBA(y int, buf []uint8) []uint8 {
+	y += r.Rect.Min.Y
+	minX, width := r.Rect.Min.X, r.Rect.Dx()
+	row := r.Pix[y*r.Stride+minX*r.Format.bytesPerPixel():]
+
+	switch r.Format {
+	case FormatRGBA:
+		return row
+	case FormatRGB24, FormatBGR24:
+		red, blue := 0, 2
+		if r.Format == FormatBGR24 {
+			red, blue = 2, 0
+		}
+		for x := range width {
+			s, d := row[x*3:x*3+3:x*3+3], buf[x*4:x*4+4:x*4+4]
+			d[0], d[1], d[2], d[3] = s[red], s[1], s[blue], 0xff
+		}
+	default:
+		for x := range width {
+			cb, cr := r.chromaAt(minX+x, y)
+			d := buf[x*4 : x*4+4 : x*4+4]
+			d[0], d[1], d[2] = color.YCbCrToRGB(row[x], cb, cr)
+			d[3] = 0xff
+		}
+	}
+	return buf
+}
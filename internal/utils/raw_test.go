@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// rawFrame encodes the opaque gradient of a width x height image in
+// format with stride bytes per luma row.
+func rawFrame(width, height, stride int, format PixelFormat) ([]byte, func(x, y int) color.Color) {
+	pixel := func(x, y int) color.NRGBA {
+		return color.NRGBA{R: uint8(x * 40), G: uint8(y * 30), B: uint8(x*y + 7), A: 0xff}
+	}
+
+	chromaWidth, chromaHeight := (width+1)/2, (height+1)/2
+	buf := make([]byte, stride*height+stride*chromaHeight)
+	for y := range height {
+		for x := range width {
+			c := pixel(x, y)
+			switch format {
+			case FormatRGB24:
+				copy(buf[y*stride+x*3:], []byte{c.R, c.G, c.B})
+			case FormatBGR24:
+				copy(buf[y*stride+x*3:], []byte{c.B, c.G, c.R})
+			case FormatRGBA:
+				copy(buf[y*stride+x*4:], []byte{c.R, c.G, c.B, c.A})
+			default:
+				buf[y*stride+x], _, _ = color.RGBToYCbCr(c.R, c.G, c.B)
+			}
+		}
+	}
+
+	// Chroma is taken from the top-left pixel of every 2x2 block.
+	chroma := func(x, y int) (uint8, uint8) {
+		c := pixel(x/2*2, y/2*2)
+		_, cb, cr := color.RGBToYCbCr(c.R, c.G, c.B)
+		return cb, cr
+	}
+	for y := range chromaHeight {
+		for x := range chromaWidth {
+			cb, cr := chroma(x*2, y*2)
+			switch format {
+			case FormatNV12:
+				buf[height*stride+y*stride+x*2], buf[height*stride+y*stride+x*2+1] = cb, cr
+			case FormatI420:
+				chromaStride := (stride + 1) / 2
+				buf[height*stride+y*chromaStride+x] = cb
+				buf[height*stride+chromaHeight*chromaStride+y*chromaStride+x] = cr
+			}
+		}
+	}
+
+	expected := func(x, y int) color.Color {
+		if format == FormatNV12 || format == FormatI420 {
+			c := pixel(x, y)
+			luma, _, _ := color.RGBToYCbCr(c.R, c.G, c.B)
+			cb, cr := chroma(x, y)
+			return color.YCbCr{Y: luma, Cb: cb, Cr: cr}
+		}
+		return pixel(x, y)
+	}
+	return buf, expected
+}
+
+func TestRawImage(t *testing.T) {
+	const width, height = 7, 5
+	for _, format := range []PixelFormat{FormatRGB24, FormatBGR24, FormatRGBA, FormatNV12, FormatI420} {
+		t.Run(format.String(), func(t *testing.T) {
+			stride := width*format.bytesPerPixel() + 3
+			buf, expected := rawFrame(width, height, stride, format)
+			img, err := NewRawImage(buf, width, height, stride, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for y := range height {
+				for x := range width {
+					if got := img.At(x, y); got != expected(x, y) {
+						t.Fatalf("expected %v at (%d, %d), got %v", expected(x, y), x, y, got)
+					}
+				}
+			}
+
+			// Rows of a sub-image starting at odd coordinates match At.
+			sub := img.SubImage(image.Rect(1, 1, width, height)).(*RawImage)
+			buf4 := make([]uint8, sub.Rect.Dx()*4)
+			for y := range sub.Rect.Dy() {
+				row := sub.RowRGBA(y, buf4)
+				for x := range sub.Rect.Dx() {
+					want := color.NRGBAModel.Convert(img.At(x+1, y+1)).(color.NRGBA)
+					got := color.NRGBA{R: row[x*4], G: row[x*4+1], B: row[x*4+2], A: row[x*4+3]}
+					if got != want {
+						t.Fatalf("expected %v in row %d at %d, got %v", want, y, x, got)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestNewRawImageValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		width   int
+		height  int
+		stride  int
+		format  PixelFormat
+		wantErr bool
+	}{
+		{name: "Tightly packed I420", size: 7*5 + 2*4*3, width: 7, height: 5, stride: 7, format: FormatI420},
+		{name: "Short I420", size: 7*5 + 2*4*3 - 1, width: 7, height: 5, stride: 7, format: FormatI420, wantErr: true},
+		{name: "NV12 without trailing padding", size: 8*4 + 8 + 6, width: 5, height: 4, stride: 8, format: FormatNV12},
+		{name: "Short NV12", size: 8*4 + 8 + 5, width: 5, height: 4, stride: 8, format: FormatNV12, wantErr: true},
+		{name: "Stride too small", size: 1000, width: 10, height: 2, stride: 29, format: FormatRGB24, wantErr: true},
+		{name: "Unknown format", size: 1000, width: 10, height: 2, stride: 40, format: PixelFormat(9), wantErr: true},
+		{name: "Empty frame", size: 1000, width: 0, height: 2, stride: 40, format: FormatRGBA, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRawImage(make([]byte, tt.size), tt.width, tt.height, tt.stride, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
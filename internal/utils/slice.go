@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"fmt"
+	"image"
+)
+
+// SliceRects tiles a width x height image into slices of at most
+// sliceWidth x sliceHeight that overlap by the given ratios. The last
+// slice of every row and column is shifted back to end at the image edge,
+// so every slice is full sized when the image is large enough. Slice
+// sizes must be positive and overlap ratios in [0, 1).
+func SliceRects(width, height, sliceWidth, sliceHeight int,
+	overlapWidthRatio, overlapHeightRatio float64,
+) ([]image.Rectangle, error) {
+	if sliceWidth <= 0 || sliceHeight <= 0 {
+		return nil, fmt.Errorf("invalid slice size %dx%d", sliceWidth, sliceHeight)
+	}
+	for _, ratio := range []float64{overlapWidthRatio, overlapHeightRatio} {
+		if ratio < 0 || ratio >= 1 {
+			return nil, fmt.Errorf("overlap ratio %v is outside [0, 1)", ratio)
+		}
+	}
+
+	sliceWidth = min(sliceWidth, width)
+	sliceHeight = min(sliceHeight, height)
+	if sliceWidth <= 0 || sliceHeight <= 0 {
+		return nil, nil
+	}
+
+	offsets := func(size, sliceSize int, overlapRatio float64) []int {
+		step := max(int(float64(sliceSize)*(1-overlapRatio)), 1)
+		result := []int{}
+		for offset := 0; ; offset += step {
+			if offset+sliceSize >= size {
+				result = append(result, size-sliceSize)
+				return result
+			}
+			result = append(result, offset)
+		}
+	}
+
+	rects := []image.Rectangle{}
+	for _, y := range offsets(height, sliceHeight, overlapHeightRatio) {
+		for _, x := range offsets(width, sliceWidth, overlapWidthRatio) {
+			rects = append(rects, image.Rect(x, y, x+sliceWidth, y+sliceHeight))
+		}
+	}
+	return rects, nil
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+func TestSliceRects(t *testing.T) {
+	tests := []struct {
+		name     string
+		width    int
+		height   int
+		expected []image.Rectangle
+	}{
+		{
+			name:   "Overlapping slices aligned to the edges",
+			width:  1000,
+			height: 600,
+			expected: []image.Rectangle{
+				image.Rect(0, 0, 512, 512), image.Rect(409, 0, 921, 512), image.Rect(488, 0, 1000, 512),
+				image.Rect(0, 88, 512, 600), image.Rect(409, 88, 921, 600), image.Rect(488, 88, 1000, 600),
+			},
+		},
+		{
+			name:     "Image smaller than a slice",
+			width:    300,
+			height:   200,
+			expected: []image.Rectangle{image.Rect(0, 0, 300, 200)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := SliceRects(tt.width, tt.height, 512, 512, 0.2, 0.2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSliceRectsInvalid(t *testing.T) {
+	tests := []struct {
+		name                        string
+		sliceWidth, sliceHeight     int
+		overlapWidth, overlapHeight float64
+	}{
+		{name: "Zero width", sliceWidth: 0, sliceHeight: 640},
+		{name: "Negative height", sliceWidth: 640, sliceHeight: -1},
+		{name: "Full overlap", sliceWidth: 640, sliceHeight: 640, overlapWidth: 1},
+		{name: "Negative overlap", sliceWidth: 640, sliceHeight: 640, overlapHeight: -0.1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rects, err := SliceRects(4000, 3000, tt.sliceWidth, tt.sliceHeight, tt.overlapWidth, tt.overlapHeight)
+			if err == nil {
+				t.Errorf("expected an error, got %d slices", len(rects))
+			}
+		})
+	}
+}
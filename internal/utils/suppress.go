@@ -0,0 +1,396 @@
+package utils
+
+import (
+	"math"
+	"sort"
+)
+
+// ISuppressor removes or merges overlapping detections. Implementations
+// return the surviving boxes ordered by descending confidence.
+type ISuppressor interface {
+	Suppress(boxes []BoundingBox, iouThreshold float32) []BoundingBox
+}
+
+// SuppressBatched runs the suppressor once per class unless agnostic is set,
+// and returns the combined result ordered by descending confidence.
+func SuppressBatched(suppressor ISuppressor, boxes []BoundingBox, iouThreshold float32, agnostic bool) []BoundingBox {
+	if agnostic {
+		return suppressor.Suppress(boxes, iouThreshold)
+	}
+
+	groups := map[int][]BoundingBox{}
+	classIDs := []int{}
+	for _, box := range boxes {
+		if _, ok := groups[box.ClassID]; !ok {
+			classIDs = append(classIDs, box.ClassID)
+		}
+		groups[box.ClassID] = append(groups[box.ClassID], box)
+	}
+
+	results := make([]BoundingBox, 0, len(boxes))
+	for _, classID := range classIDs {
+		results = append(results, suppressor.Suppress(groups[classID], iouThreshold)...)
+	}
+	sortByConfidence(results)
+	return results
+}
+
+func sortByConfidence(boxes []BoundingBox) {
+	sort.SliceStable(boxes, func(i, j int) bool {
+		return boxes[i].Confidence > boxes[j].Confidence
+	})
+}
+
+func sortedCopy(boxes []BoundingBox) []BoundingBox {
+	sorted := make([]BoundingBox, len(boxes))
+	copy(sorted, boxes)
+	sortByConfidence(sorted)
+	return sorted
+}
+
+// MatchMetric measures how much two boxes overlap.
+type MatchMetric int
+
+const (
+	// MatchIoU is intersection over union.
+	MatchIoU MatchMetric = iota
+	// MatchIoS is intersection over the area of the smaller box, which
+	// matches a partial box cut at a slice border with the full one.
+	MatchIoS
+)
+
+func (m MatchMetric) overlap(a, b *BoundingBox) float32 {
+	if m == MatchIoS {
+		return a.IoS(b)
+	}
+	return a.IoU(b)
+}
+
+// GreedyNMS is the classic hard suppression: a box is dropped when it
+// overlaps a higher scoring box by at least the threshold, like the
+// built-in NMS.
+type GreedyNMS struct {
+	Metric MatchMetric
+}
+
+func (s *GreedyNMS) Suppress(boxes []BoundingBox, iouThreshold float32) []BoundingBox {
+	sorted := sortedCopy(boxes)
+	suppressed := make([]bool, len(sorted))
+	results := make([]BoundingBox, 0, len(sorted))
+
+	for i := range sorted {
+		if suppressed[i] {
+			continue
+		}
+		results = append(results, sorted[i])
+		for j := i + 1; j < len(sorted); j++ {
+			if !suppressed[j] && s.Metric.overlap(&sorted[i], &sorted[j]) >= iouThreshold {
+				suppressed[j] = true
+			}
+		}
+	}
+	return results
+}
+
+// NonMaximumMerging merges overlapping boxes instead of dropping them: the
+// highest scoring box of a group grows to the union of the group and keeps
+// its label and confidence. Greedy merging only groups boxes matching the
+// highest scoring box itself; otherwise a group keeps absorbing boxes that
+// match the merged box until it stops growing.
+type NonMaximumMerging struct {
+	Metric MatchMetric
+	Greedy bool
+}
+
+func (s *NonMaximumMerging) Suppress(boxes []BoundingBox, iouThreshold float32) []BoundingBox {
+	sorted := sortedCopy(boxes)
+	merged := make([]bool, len(sorted))
+	results := make([]BoundingBox, 0, len(sorted))
+
+	for i := range sorted {
+		if merged[i] {
+			continue
+		}
+		current := sorted[i]
+		reference := sorted[i]
+		for grown := true; grown; {
+			grown = false
+			for j := i + 1; j < len(sorted); j++ {
+				if merged[j] || s.Metric.overlap(&reference, &sorted[j]) <= iouThreshold {
+					continue
+				}
+				merged[j] = true
+				current.X1, current.Y1 = min(current.X1, sorted[j].X1), min(current.Y1, sorted[j].Y1)
+				current.X2, current.Y2 = max(current.X2, sorted[j].X2), max(current.Y2, sorted[j].Y2)
+				grown = !s.Greedy
+			}
+			reference = current
+		}
+		results = append(results, current)
+	}
+	return results
+}
+
+type DecayMethod int
+
+const (
+	DecayLinear DecayMethod = iota
+	DecayGaussian
+)
+
+// SoftNMS decays the confidence of overlapping boxes instead of removing
+// them (Bodla et al., 2017). Boxes whose decayed confidence falls below
+// ScoreThreshold are dropped.
+type SoftNMS struct {
+	Method         DecayMethod
+	Sigma          float32
+	ScoreThreshold float32
+}
+
+func NewSoftNMS(method DecayMethod) *SoftNMS {
+	return &SoftNMS{Method: method, Sigma: 0.5, ScoreThreshold: 0.001}
+}
+
+func (s *SoftNMS) Suppress(boxes []BoundingBox, iouThreshold float32) []BoundingBox {
+	remaining := make([]BoundingBox, len(boxes))
+	copy(remaining, boxes)
+	results := make([]BoundingBox, 0, len(boxes))
+
+	for len(remaining) > 0 {
+		maxIdx := 0
+		for i := range remaining {
+			if remaining[i].Confidence > remaining[maxIdx].Confidence {
+				maxIdx = i
+			}
+		}
+		current := remaining[maxIdx]
+		results = append(results, current)
+		remaining[maxIdx] = remaining[len(remaining)-1]
+		remaining = remaining[:len(remaining)-1]
+
+		kept := remaining[:0]
+		for _, box := range remaining {
+			iou := current.IoU(&box)
+			switch s.Method {
+			case DecayGaussian:
+				box.Confidence *= float32(math.Exp(-float64(iou*iou) / float64(s.Sigma)))
+			default:
+				if iou > iouThreshold {
+					box.Confidence *= 1 - iou
+				}
+			}
+			if box.Confidence >= s.ScoreThreshold {
+				kept = append(kept, box)
+			}
+		}
+		remaining = kept
+	}
+	return results
+}
+
+// DIoUNMS suppresses with Distance-IoU, which penalises the overlap by the
+// distance between box centres so adjacent objects survive (Zheng et al.,
+// 2020). With CIoU set the aspect ratio consistency term is added as well.
+type DIoUNMS struct {
+	CIoU bool
+}
+
+func (s *DIoUNMS) Suppress(boxes []BoundingBox, iouThreshold float32) []BoundingBox {
+	sorted := sortedCopy(boxes)
+	suppressed := make([]bool, len(sorted))
+	results := make([]BoundingBox, 0, len(sorted))
+
+	for i := range sorted {
+		if suppressed[i] {
+			continue
+		}
+		results = append(results, sorted[i])
+		for j := i + 1; j < len(sorted); j++ {
+			if suppressed[j] {
+				continue
+			}
+			var overlap float32
+			if s.CIoU {
+				overlap = CIoU(&sorted[i], &sorted[j])
+			} else {
+				overlap = DIoU(&sorted[i], &sorted[j])
+			}
+			if overlap > iouThreshold {
+				suppressed[j] = true
+			}
+		}
+	}
+	return results
+}
+
+// centerDistance returns the squared distance between the box centres and
+// the squared diagonal of the smallest box enclosing both.
+func centerDistance(a, b *BoundingBox) (float32, float32) {
+	dx := (a.X1 + a.X2 - b.X1 - b.X2) / 2
+	dy := (a.Y1 + a.Y2 - b.Y1 - b.Y2) / 2
+	cw := max(a.X2, b.X2) - min(a.X1, b.X1)
+	ch := max(a.Y2, b.Y2) - min(a.Y1, b.Y1)
+	return dx*dx + dy*dy, cw*cw + ch*ch
+}
+
+func DIoU(a, b *BoundingBox) float32 {
+	iou := a.IoU(b)
+	distance, diagonal := centerDistance(a, b)
+	if diagonal <= 0 {
+		return iou
+	}
+	return iou - distance/diagonal
+}
+
+func CIoU(a, b *BoundingBox) float32 {
+	iou := a.IoU(b)
+	distance, diagonal := centerDistance(a, b)
+	if diagonal <= 0 || a.Height() <= 0 || b.Height() <= 0 {
+		return iou
+	}
+
+	arctan := math.Atan(float64(b.Width()/b.Height())) - math.Atan(float64(a.Width()/a.Height()))
+	v := float32(4 / (math.Pi * math.Pi) * arctan * arctan)
+	var alpha float32
+	if denominator := 1 - iou + v; denominator > 0 {
+		alpha = v / denominator
+	}
+	return iou - distance/diagonal - alpha*v
+}
+
+// MatrixNMS decays every score in parallel from the pairwise IoU matrix
+// (Wang et al., SOLOv2). It ignores the IoU threshold; boxes whose decayed
+// confidence falls below ScoreThreshold are dropped.
+type MatrixNMS struct {
+	Kernel         DecayMethod
+	Sigma          float32
+	ScoreThreshold float32
+}
+
+func NewMatrixNMS(kernel DecayMethod) *MatrixNMS {
+	return &MatrixNMS{Kernel: kernel, Sigma: 2.0, ScoreThreshold: 0.001}
+}
+
+func (s *MatrixNMS) Suppress(boxes []BoundingBox, iouThreshold float32) []BoundingBox {
+	sorted := sortedCopy(boxes)
+	n := len(sorted)
+
+	// compensate[i] is the largest IoU of box i with any higher scoring box.
+	ious := make([]float32, n*n)
+	compensate := make([]float32, n)
+	for j := 0; j < n; j++ {
+		for i := 0; i < j; i++ {
+			iou := sorted[i].IoU(&sorted[j])
+			ious[i*n+j] = iou
+			compensate[j] = max(compensate[j], iou)
+		}
+	}
+
+	results := make([]BoundingBox, 0, n)
+	for j := 0; j < n; j++ {
+		decay := float32(1)
+		for i := 0; i < j; i++ {
+			iou := ious[i*n+j]
+			var factor float32
+			if s.Kernel == DecayGaussian {
+				factor = float32(math.Exp(-float64(iou*iou-compensate[i]*compensate[i]) * float64(s.Sigma)))
+			} else {
+				factor = (1 - iou) / max(1-compensate[i], 1e-6)
+			}
+			decay = min(decay, factor)
+		}
+
+		box := sorted[j]
+		box.Confidence *= decay
+		if box.Confidence >= s.ScoreThreshold {
+			results = append(results, box)
+		}
+	}
+	sortByConfidence(results)
+	return results
+}
+
+type FusionConfidence int
+
+const (
+	FusionAverage FusionConfidence = iota
+	FusionMax
+)
+
+// WeightedBoxesFusion merges clusters of overlapping boxes of the same class
+// into one box whose coordinates are the confidence weighted average of the
+// cluster (Solovyev et al., 2019). Models is the number of prediction sets
+// that were concatenated into the input; clusters seen by fewer of them get
+// a proportionally lower confidence.
+type WeightedBoxesFusion struct {
+	SkipBoxThreshold float32
+	Confidence       FusionConfidence
+	Models           int
+}
+
+func (s *WeightedBoxesFusion) Suppress(boxes []BoundingBox, iouThreshold float32) []BoundingBox {
+	sorted := make([]BoundingBox, 0, len(boxes))
+	for _, box := range boxes {
+		if box.Confidence >= s.SkipBoxThreshold {
+			sorted = append(sorted, box)
+		}
+	}
+	sortByConfidence(sorted)
+
+	models := max(s.Models, 1)
+	fused := []BoundingBox{}
+	clusters := [][]BoundingBox{}
+
+	for _, box := range sorted {
+		match := -1
+		bestIoU := iouThreshold
+		for i := range fused {
+			if fused[i].ClassID != box.ClassID {
+				continue
+			}
+			if iou := fused[i].IoU(&box); iou > bestIoU {
+				match = i
+				bestIoU = iou
+			}
+		}
+
+		if match < 0 {
+			fused = append(fused, box)
+			clusters = append(clusters, []BoundingBox{box})
+			continue
+		}
+		clusters[match] = append(clusters[match], box)
+		fused[match] = fuseCluster(clusters[match])
+	}
+
+	for i, cluster := range clusters {
+		var confidence float32
+		if s.Confidence == FusionMax {
+			confidence = cluster[0].Confidence
+		} else {
+			confidence = fused[i].Confidence
+		}
+		fused[i].Confidence = confidence * float32(min(len(cluster), models)) / float32(models)
+	}
+	sortByConfidence(fused)
+	return fused
+}
+
+// fuseCluster averages the cluster coordinates weighted by confidence. The
+// fused confidence is the mean confidence of the cluster.
+func fuseCluster(cluster []BoundingBox) BoundingBox {
+	fused := cluster[0]
+	var x1, y1, x2, y2, weights float32
+	for _, box := range cluster {
+		x1 += box.Confidence * box.X1
+		y1 += box.Confidence * box.Y1
+		x2 += box.Confidence * box.X2
+		y2 += box.Confidence * box.Y2
+		weights += box.Confidence
+	}
+	if weights > 0 {
+		fused.X1, fused.Y1, fused.X2, fused.Y2 = x1/weights, y1/weights, x2/weights, y2/weights
+	}
+	fused.Confidence = weights / float32(len(cluster))
+	return fused
+}
@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func suppressionBoxes() []BoundingBox {
+	return []BoundingBox{
+		{Label: "a", Confidence: 0.8, X1: 1, Y1: 0, X2: 11, Y2: 10},
+		{Label: "b", Confidence: 0.9, X1: 0, Y1: 0, X2: 10, Y2: 10},
+		{Label: "c", Confidence: 0.7, X1: 20, Y1: 20, X2: 30, Y2: 30},
+	}
+}
+
+type expectedBox struct {
+	label      string
+	confidence float32
+}
+
+func assertBoxes(t *testing.T, result []BoundingBox, expected []expectedBox) {
+	t.Helper()
+	if len(result) != len(expected) {
+		t.Fatalf("expected %d boxes, got %+v", len(expected), result)
+	}
+	for i, e := range expected {
+		if result[i].Label != e.label || math.Abs(float64(result[i].Confidence-e.confidence)) > 1e-5 {
+			t.Errorf("box %d: expected %s (%f), got %s (%f)", i, e.label, e.confidence, result[i].Label, result[i].Confidence)
+		}
+	}
+}
+
+func TestBoundingBoxIoU(t *testing.T) {
+	a := BoundingBox{X1: 0, Y1: 0, X2: 100, Y2: 100}
+	b := BoundingBox{X1: 50, Y1: 50, X2: 150, Y2: 150}
+	c := BoundingBox{X1: 200, Y1: 200, X2: 300, Y2: 300}
+
+	if iou := a.IoU(&b); math.Abs(float64(iou)-0.142857) > 1e-6 {
+		t.Errorf("expected IoU 0.142857, got %f", iou)
+	}
+	if iou := a.IoU(&c); iou != 0 {
+		t.Errorf("expected IoU 0, got %f", iou)
+	}
+}
+
+func TestGreedyNMS(t *testing.T) {
+	result := (&GreedyNMS{}).Suppress(suppressionBoxes(), 0.5)
+	assertBoxes(t, result, []expectedBox{{"b", 0.9}, {"c", 0.7}})
+}
+
+func TestGreedyNMSMatchesBuiltinAtThreshold(t *testing.T) {
+	// The IoU of the two boxes is exactly 0.5.
+	boxes := []BoundingBox{
+		{Label: "a", Confidence: 0.9, X1: 0, Y1: 0, X2: 10, Y2: 10},
+		{Label: "b", Confidence: 0.8, X1: 0, Y1: 0, X2: 10, Y2: 5},
+	}
+
+	result := (&GreedyNMS{}).Suppress(boxes, 0.5)
+	assertBoxes(t, result, []expectedBox{{"a", 0.9}})
+
+	floatBoxes := []Box{{X1: 0, Y1: 0, X2: 10, Y2: 10}, {X1: 0, Y1: 0, X2: 10, Y2: 5}}
+	scores := []float32{0.9, 0.8}
+	classIDs := []int{0, 0}
+	indices := (&ImageUtils{}).NMSBoxesBatched(&floatBoxes, &scores, &classIDs, 0, 0.5, false, 0)
+	if len(*indices) != len(result) {
+		t.Errorf("expected the built-in NMS to keep %d boxes, got %v", len(result), *indices)
+	}
+}
+
+func TestSoftNMS(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   DecayMethod
+		expected []expectedBox
+	}{
+		{name: "Linear", method: DecayLinear, expected: []expectedBox{{"b", 0.9}, {"c", 0.7}, {"a", 0.145455}}},
+		{name: "Gaussian", method: DecayGaussian, expected: []expectedBox{{"b", 0.9}, {"c", 0.7}, {"a", 0.209719}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewSoftNMS(tt.method).Suppress(suppressionBoxes(), 0.5)
+			assertBoxes(t, result, tt.expected)
+		})
+	}
+}
+
+func TestSoftNMSScoreThreshold(t *testing.T) {
+	suppressor := NewSoftNMS(DecayLinear)
+	suppressor.ScoreThreshold = 0.2
+
+	result := suppressor.Suppress(suppressionBoxes(), 0.5)
+	assertBoxes(t, result, []expectedBox{{"b", 0.9}, {"c", 0.7}})
+}
+
+func TestDIoUNMS(t *testing.T) {
+	// IoU 0.5 with centres 5px apart: DIoU 0.45, CIoU ~0.4468.
+	boxes := []BoundingBox{
+		{Label: "square", Confidence: 0.9, X1: 0, Y1: 0, X2: 10, Y2: 10},
+		{Label: "tall", Confidence: 0.8, X1: 0, Y1: 0, X2: 10, Y2: 20},
+	}
+
+	if diou := DIoU(&boxes[0], &boxes[1]); math.Abs(float64(diou)-0.45) > 1e-6 {
+		t.Errorf("expected DIoU 0.45, got %f", diou)
+	}
+	if ciou := CIoU(&boxes[0], &boxes[1]); math.Abs(float64(ciou)-0.446752) > 1e-6 {
+		t.Errorf("expected CIoU 0.446752, got %f", ciou)
+	}
+
+	assertBoxes(t, (&GreedyNMS{}).Suppress(boxes, 0.48), []expectedBox{{"square", 0.9}})
+	assertBoxes(t, (&DIoUNMS{}).Suppress(boxes, 0.48), []expectedBox{{"square", 0.9}, {"tall", 0.8}})
+	assertBoxes(t, (&DIoUNMS{}).Suppress(boxes, 0.44), []expectedBox{{"square", 0.9}})
+	assertBoxes(t, (&DIoUNMS{CIoU: true}).Suppress(boxes, 0.446), []expectedBox{{"square", 0.9}})
+	assertBoxes(t, (&DIoUNMS{CIoU: true}).Suppress(boxes, 0.447), []expectedBox{{"square", 0.9}, {"tall", 0.8}})
+}
+
+func TestMatrixNMS(t *testing.T) {
+	tests := []struct {
+		name     string
+		kernel   DecayMethod
+		expected []expectedBox
+	}{
+		{name: "Linear", kernel: DecayLinear, expected: []expectedBox{{"b", 0.9}, {"c", 0.7}, {"a", 0.145455}}},
+		{name: "Gaussian", kernel: DecayGaussian, expected: []expectedBox{{"b", 0.9}, {"c", 0.7}, {"a", 0.209719}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewMatrixNMS(tt.kernel).Suppress(suppressionBoxes(), 0.5)
+			assertBoxes(t, result, tt.expected)
+		})
+	}
+}
+
+func TestWeightedBoxesFusion(t *testing.T) {
+	suppressor := &WeightedBoxesFusion{Models: 1}
+	result := suppressor.Suppress(suppressionBoxes(), 0.55)
+	assertBoxes(t, result, []expectedBox{{"b", 0.85}, {"c", 0.7}})
+
+	fused := result[0]
+	if math.Abs(float64(fused.X1)-0.470588) > 1e-5 || math.Abs(float64(fused.X2)-10.470588) > 1e-5 ||
+		fused.Y1 != 0 || fused.Y2 != 10 {
+		t.Errorf("unexpected fused box %+v", fused)
+	}
+
+	suppressor = &WeightedBoxesFusion{Models: 2, Confidence: FusionMax}
+	result = suppressor.Suppress(suppressionBoxes(), 0.55)
+	assertBoxes(t, result, []expectedBox{{"b", 0.9}, {"c", 0.35}})
+}
+
+func TestSuppressBatched(t *testing.T) {
+	boxes := suppressionBoxes()
+	boxes[0].ClassID = 1
+
+	result := SuppressBatched(&GreedyNMS{}, boxes, 0.5, false)
+	assertBoxes(t, result, []expectedBox{{"b", 0.9}, {"a", 0.8}, {"c", 0.7}})
+
+	result = SuppressBatched(&GreedyNMS{}, boxes, 0.5, true)
+	assertBoxes(t, result, []expectedBox{{"b", 0.9}, {"c", 0.7}})
+}
+
+func TestBoundingBoxIoS(t *testing.T) {
+	full := BoundingBox{X1: 0, Y1: 0, X2: 100, Y2: 100}
+	cut := BoundingBox{X1: 50, Y1: 0, X2: 100, Y2: 100}
+
+	if ios := full.IoS(&cut); ios != 1 {
+		t.Errorf("expected IoS 1, got %f", ios)
+	}
+	if iou := full.IoU(&cut); iou != 0.5 {
+		t.Errorf("expected IoU 0.5, got %f", iou)
+	}
+}
+
+func TestNonMaximumMerging(t *testing.T) {
+	// q extends p to the right and r only overlaps the extension.
+	boxes := []BoundingBox{
+		{Label: "p", Confidence: 0.9, X1: 0, Y1: 0, X2: 100, Y2: 100},
+		{Label: "q", Confidence: 0.8, X1: 50, Y1: 0, X2: 120, Y2: 100},
+		{Label: "r", Confidence: 0.7, X1: 102, Y1: 0, X2: 125, Y2: 100},
+	}
+
+	result := (&NonMaximumMerging{Metric: MatchIoS, Greedy: true}).Suppress(boxes, 0.5)
+	assertBoxes(t, result, []expectedBox{{"p", 0.9}, {"r", 0.7}})
+	if result[0].X1 != 0 || result[0].X2 != 120 {
+		t.Errorf("expected p merged with q, got %+v", result[0])
+	}
+
+	result = (&NonMaximumMerging{Metric: MatchIoS}).Suppress(boxes, 0.5)
+	assertBoxes(t, result, []expectedBox{{"p", 0.9}})
+	if result[0].X1 != 0 || result[0].X2 != 125 {
+		t.Errorf("expected p merged with q and r, got %+v", result[0])
+	}
+
+	result = (&NonMaximumMerging{Metric: MatchIoU, Greedy: true}).Suppress(boxes, 0.5)
+	assertBoxes(t, result, []expectedBox{{"p", 0.9}, {"q", 0.8}, {"r", 0.7}})
+}
+
+func TestGreedyNMSIoS(t *testing.T) {
+	boxes := []BoundingBox{
+		{Label: "full", Confidence: 0.9, X1: 0, Y1: 0, X2: 100, Y2: 100},
+		{Label: "cut", Confidence: 0.8, X1: 50, Y1: 0, X2: 100, Y2: 100},
+	}
+
+	assertBoxes(t, (&GreedyNMS{}).Suppress(boxes, 0.6), []expectedBox{{"full", 0.9}, {"cut", 0.8}})
+	assertBoxes(t, (&GreedyNMS{Metric: MatchIoS}).Suppress(boxes, 0.6), []expectedBox{{"full", 0.9}})
+}
@@ -0,0 +1,33 @@
+package yolo
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"os"
+
+	"github.com/disintegration/imaging"
+)
+
+// LoadImage decodes the image file at path and applies its EXIF
+// orientation, so detections come back in the orientation the photo is
+// viewed in rather than the one the camera stored.
+func LoadImage(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	img, err := DecodeImage(f)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s: %w", path, err)
+	}
+	return img, nil
+}
+
+// DecodeImage decodes an image and applies its EXIF orientation. Images
+// without one are returned as decoded, so JPEGs keep their YCbCr fast path.
+func DecodeImage(r io.Reader) (image.Image, error) {
+	return imaging.Decode(r, imaging.AutoOrientation(true))
+}
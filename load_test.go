@@ -0,0 +1,75 @@
+package yolo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+// withOrientation inserts an EXIF segment with the given orientation after
+// the SOI marker of a JPEG.
+func withOrientation(t *testing.T, img image.Image, orientation uint16) []byte {
+	t.Helper()
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	var exif bytes.Buffer
+	exif.WriteString("Exif\x00\x00")
+	exif.WriteString("MM\x00\x2a")
+	// IFD0 at offset 8 holding a single SHORT orientation entry.
+	for _, value := range []any{uint32(8), uint16(1), uint16(0x0112), uint16(3), uint32(1), orientation, uint16(0), uint32(0)} {
+		if err := binary.Write(&exif, binary.BigEndian, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	out.Write(encoded.Bytes()[:2])
+	binary.Write(&out, binary.BigEndian, []uint16{0xffe1, uint16(exif.Len() + 2)})
+	out.Write(exif.Bytes())
+	out.Write(encoded.Bytes()[2:])
+	return out.Bytes()
+}
+
+func TestDecodeImageOrientation(t *testing.T) {
+	// A 32x16 image with a white block in its stored top-left corner.
+	img := image.NewGray(image.Rect(0, 0, 32, 16))
+	for y := range 8 {
+		for x := range 8 {
+			img.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+
+	tests := []struct {
+		name        string
+		orientation uint16
+		size        image.Point
+		corner      image.Point
+	}{
+		{name: "Normal", orientation: 1, size: image.Pt(32, 16), corner: image.Pt(2, 2)},
+		{name: "Rotated 90 clockwise", orientation: 6, size: image.Pt(16, 32), corner: image.Pt(13, 2)},
+		{name: "Rotated 180", orientation: 3, size: image.Pt(32, 16), corner: image.Pt(29, 13)},
+		{name: "Mirrored", orientation: 2, size: image.Pt(32, 16), corner: image.Pt(29, 2)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := DecodeImage(bytes.NewReader(withOrientation(t, img, tt.orientation)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decoded.Bounds().Size() != tt.size {
+				t.Fatalf("expected size %v, got %v", tt.size, decoded.Bounds().Size())
+			}
+			corner := decoded.Bounds().Min.Add(tt.corner)
+			if r, _, _, _ := decoded.At(corner.X, corner.Y).RGBA(); r < 0xf000 {
+				t.Errorf("expected the white block at %v, got %d", tt.corner, r)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package yolo
+
+import (
+	"image"
+
+	models "github.com/zazamaza/yolo-object-detection-go/internal/models"
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+type (
+	Point            = utils.Point
+	Polygon          = utils.Polygon
+	ExclusionMask    = utils.ExclusionMask
+	LetterboxOptions = utils.LetterboxOptions
+	Interpolation    = utils.Interpolation
+	PadPosition      = utils.PadPosition
+	Normalization    = models.Normalization
+	ChannelOrder     = models.ChannelOrder
+	TensorLayout     = models.TensorLayout
+	PixelFormat      = utils.PixelFormat
+)
+
+const (
+	ExcludeByCenter  = utils.ExcludeByCenter
+	ExcludeByOverlap = utils.ExcludeByOverlap
+)
+
+const (
+	InterpolationBilinear = utils.InterpolationBilinear
+	InterpolationNearest  = utils.InterpolationNearest
+	InterpolationArea     = utils.InterpolationArea
+	InterpolationLanczos  = utils.InterpolationLanczos
+	PadCenter             = utils.PadCenter
+	PadTopLeft            = utils.PadTopLeft
+	ChannelsRGB           = models.ChannelsRGB
+	ChannelsBGR           = models.ChannelsBGR
+	LayoutNCHW            = models.LayoutNCHW
+	LayoutNHWC            = models.LayoutNHWC
+	FormatRGB24           = utils.FormatRGB24
+	FormatBGR24           = utils.FormatBGR24
+	FormatRGBA            = utils.FormatRGBA
+	FormatNV12            = utils.FormatNV12
+	FormatI420            = utils.FormatI420
+)
+
+// PredictOptions holds the settings of a single Predict call. Each model
+// starts from the defaults it was constructed with.
+type PredictOptions struct {
+	ScoreThreshold float32
+	NMSThreshold   float32
+	// MaxDetections caps the detections returned; zero means no cap.
+	MaxDetections int
+	// ClassThresholds overrides ScoreThreshold for individual labels.
+	ClassThresholds map[string]float32
+	// IncludeClasses keeps only these labels when not empty.
+	IncludeClasses []string
+	// ExcludeClasses drops these labels.
+	ExcludeClasses []string
+	// MaxPerClass caps the detections returned per label; zero means no cap.
+	MaxPerClass int
+	// ROI restricts detection to a rectangle of the input image. Boxes are
+	// still returned in full image coordinates. An empty ROI means the
+	// whole image.
+	ROI image.Rectangle
+	// ExclusionMasks drop detections in static regions of the frame, given
+	// in full image coordinates. Excluded candidates are dropped before NMS
+	// and the detection caps.
+	ExclusionMasks []ExclusionMask
+	// AgnosticNMS lets boxes of different classes suppress each other.
+	AgnosticNMS bool
+	// RawCandidates skips NMS and returns every candidate above its
+	// score threshold.
+	RawCandidates bool
+}
+
+type PredictOption func(*PredictOptions)
+
+func WithScoreThreshold(threshold float32) PredictOption {
+	return func(o *PredictOptions) {
+		o.ScoreThreshold = threshold
+	}
+}
+
+func WithNMSThreshold(threshold float32) PredictOption {
+	return func(o *PredictOptions) {
+		o.NMSThreshold = threshold
+	}
+}
+
+func WithMaxDetections(maxDetections int) PredictOption {
+	return func(o *PredictOptions) {
+		o.MaxDetections = maxDetections
+	}
+}
+
+func WithClassThresholds(thresholds map[string]float32) PredictOption {
+	return func(o *PredictOptions) {
+		o.ClassThresholds = thresholds
+	}
+}
+
+func WithClasses(labels ...string) PredictOption {
+	return func(o *PredictOptions) {
+		o.IncludeClasses = labels
+	}
+}
+
+func WithoutClasses(labels ...string) PredictOption {
+	return func(o *PredictOptions) {
+		o.ExcludeClasses = labels
+	}
+}
+
+func WithMaxPerClass(maxPerClass int) PredictOption {
+	return func(o *PredictOptions) {
+		o.MaxPerClass = maxPerClass
+	}
+}
+
+func WithROI(roi image.Rectangle) PredictOption {
+	return func(o *PredictOptions) {
+		o.ROI = roi
+	}
+}
+
+func WithExclusionMasks(masks ...ExclusionMask) PredictOption {
+	return func(o *PredictOptions) {
+		o.ExclusionMasks = masks
+	}
+}
+
+func WithAgnosticNMS(agnostic bool) PredictOption {
+	return func(o *PredictOptions) {
+		o.AgnosticNMS = agnostic
+	}
+}
+
+func WithRawCandidates() PredictOption {
+	return func(o *PredictOptions) {
+		o.RawCandidates = true
+	}
+}
+
+func defaultPredictOptions(configuration *models.YOLOConfiguration) PredictOptions {
+	return PredictOptions{
+		ScoreThreshold: configuration.ScoreThreshold,
+		NMSThreshold:   configuration.NMSThreshold,
+		MaxDetections:  configuration.MaxDetections,
+		AgnosticNMS:    configuration.AgnosticNMS,
+	}
+}
+
+func (o *PredictOptions) apply(opts []PredictOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+func (o *PredictOptions) postProcessOptions() *models.PostProcessOptions {
+	return &models.PostProcessOptions{
+		ScoreThreshold:  o.ScoreThreshold,
+		NMSThreshold:    o.NMSThreshold,
+		ClassThresholds: o.ClassThresholds,
+		IncludeClasses:  o.IncludeClasses,
+		ExcludeClasses:  o.ExcludeClasses,
+		MaxPerClass:     o.MaxPerClass,
+		MaxDetections:   o.MaxDetections,
+		Agnostic:        o.AgnosticNMS,
+		SkipNMS:         o.RawCandidates,
+		ExclusionMasks:  o.ExclusionMasks,
+	}
+}
@@ -0,0 +1,48 @@
+package yolo
+
+import (
+	"image"
+	"reflect"
+	"testing"
+
+	models "github.com/zazamaza/yolo-object-detection-go/internal/models"
+)
+
+func TestPredictOptions(t *testing.T) {
+	configuration := models.NewYOLOConfiguration()
+	configuration.AgnosticNMS = true
+
+	options := defaultPredictOptions(&configuration)
+	options.apply([]PredictOption{
+		WithScoreThreshold(0.4),
+		WithClasses("Head"),
+		WithoutClasses("Flashed"),
+		WithClassThresholds(map[string]float32{"Enemy": 0.1}),
+		WithMaxPerClass(2),
+		WithROI(image.Rect(10, 10, 100, 100)),
+		WithExclusionMasks(ExclusionMask{Polygon: Polygon{{X: 0, Y: 0}, {X: 5, Y: 0}, {X: 5, Y: 5}}}),
+		WithRawCandidates(),
+	})
+
+	expected := PredictOptions{
+		ScoreThreshold:  0.4,
+		NMSThreshold:    0.45,
+		MaxDetections:   300,
+		ClassThresholds: map[string]float32{"Enemy": 0.1},
+		IncludeClasses:  []string{"Head"},
+		ExcludeClasses:  []string{"Flashed"},
+		MaxPerClass:     2,
+		ROI:             image.Rect(10, 10, 100, 100),
+		ExclusionMasks:  []ExclusionMask{{Polygon: Polygon{{X: 0, Y: 0}, {X: 5, Y: 0}, {X: 5, Y: 5}}}},
+		AgnosticNMS:     true,
+		RawCandidates:   true,
+	}
+	if !reflect.DeepEqual(options, expected) {
+		t.Errorf("expected %+v, got %+v", expected, options)
+	}
+
+	postProcessOptions := options.postProcessOptions()
+	if !postProcessOptions.Agnostic || !postProcessOptions.SkipNMS || postProcessOptions.MaxDetections != 300 {
+		t.Errorf("unexpected post-process options %+v", postProcessOptions)
+	}
+}
@@ -0,0 +1,196 @@
+package yolo
+
+import (
+	"encoding/json"
+	"image"
+	"math"
+	"time"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// ResultSchemaVersion is the version of the Result JSON schema. It only
+// changes when fields are renamed or removed, never when they are added.
+const ResultSchemaVersion = 1
+
+// Result is the detections of one image with the context needed to use
+// them elsewhere. It marshals to:
+//
+//	{
+//	  "schema_version": 1,
+//	  "image": {"width": 1280, "height": 720},
+//	  "model": {"name": "yolo11n", "version": "yolo11"},
+//	  "timings": {"preprocess_ms": 1.2, "inference_ms": 8.4, "postprocess_ms": 0.3, "total_ms": 9.9},
+//	  "detections": [{
+//	    "class_id": 0, "label": "person", "score": 0.91,
+//	    "box": {
+//	      "xyxy": [100, 50, 300, 650], "xywh": [200, 350, 200, 600],
+//	      "xyxyn": [0.078125, 0.06944, 0.234375, 0.90278], "xywhn": [0.15625, 0.48611, 0.15625, 0.83333]
+//	    }
+//	  }]
+//	}
+type Result struct {
+	SchemaVersion int         `json:"schema_version"`
+	Image         ImageSize   `json:"image"`
+	Model         ModelInfo   `json:"model"`
+	Timings       Timings     `json:"timings"`
+	Detections    []Detection `json:"detections"`
+}
+
+// ImageSize is the size of the image the boxes refer to, in pixels.
+type ImageSize struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// ModelInfo identifies the model that produced a result.
+type ModelInfo struct {
+	// Name is the model file name without its extension.
+	Name string `json:"name"`
+	// Version is the YOLO architecture, such as "yolov8" or "yolo11".
+	Version string `json:"version"`
+}
+
+// Timings are the durations of the stages of a prediction. They marshal
+// as fractional milliseconds, plus their total.
+type Timings struct {
+	Preprocess  time.Duration
+	Inference   time.Duration
+	Postprocess time.Duration
+}
+
+// Total returns the sum of the stages.
+func (t Timings) Total() time.Duration {
+	return t.Preprocess + t.Inference + t.Postprocess
+}
+
+type timingsJSON struct {
+	Preprocess  float64 `json:"preprocess_ms"`
+	Inference   float64 `json:"inference_ms"`
+	Postprocess float64 `json:"postprocess_ms"`
+	Total       float64 `json:"total_ms"`
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func duration(ms float64) time.Duration {
+	return time.Duration(math.Round(ms * float64(time.Millisecond)))
+}
+
+func (t Timings) MarshalJSON() ([]byte, error) {
+	return json.Marshal(timingsJSON{
+		Preprocess:  milliseconds(t.Preprocess),
+		Inference:   milliseconds(t.Inference),
+		Postprocess: milliseconds(t.Postprocess),
+		Total:       milliseconds(t.Total()),
+	})
+}
+
+// UnmarshalJSON reads the stages; the total is derived from them.
+func (t *Timings) UnmarshalJSON(data []byte) error {
+	var v timingsJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*t = Timings{Preprocess: duration(v.Preprocess), Inference: duration(v.Inference), Postprocess: duration(v.Postprocess)}
+	return nil
+}
+
+// Detection is one detected object.
+type Detection struct {
+	ClassID int     `json:"class_id"`
+	Label   string  `json:"label"`
+	Score   float32 `json:"score"`
+	Box     Box     `json:"box"`
+}
+
+// Box holds a box in the four forms used by Ultralytics: corners (xyxy)
+// and centre with size (xywh), in pixels and normalized to 0-1 by the
+// image size (xyxyn, xywhn).
+type Box struct {
+	XYXY  [4]float32 `json:"xyxy"`
+	XYWH  [4]float32 `json:"xywh"`
+	XYXYN [4]float32 `json:"xyxyn"`
+	XYWHN [4]float32 `json:"xywhn"`
+}
+
+// NewBox returns box in every form. The normalized forms are zero when
+// the image size is unknown.
+func NewBox(box *utils.BoundingBox, size ImageSize) Box {
+	b := Box{
+		XYXY: [4]float32{box.X1, box.Y1, box.X2, box.Y2},
+		XYWH: [4]float32{(box.X1 + box.X2) / 2, (box.Y1 + box.Y2) / 2, box.X2 - box.X1, box.Y2 - box.Y1},
+	}
+	if size.Width > 0 && size.Height > 0 {
+		w, h := float32(size.Width), float32(size.Height)
+		for i, scale := range [4]float32{w, h, w, h} {
+			b.XYXYN[i] = b.XYXY[i] / scale
+			b.XYWHN[i] = b.XYWH[i] / scale
+		}
+	}
+	return b
+}
+
+// NewResult describes boxes detected in an image of the given size. The
+// model and timings are left for the caller to fill in.
+func NewResult(boxes []utils.BoundingBox, size image.Point) *Result {
+	result := &Result{
+		SchemaVersion: ResultSchemaVersion,
+		Image:         ImageSize{Width: size.X, Height: size.Y},
+		Detections:    make([]Detection, len(boxes)),
+	}
+	for i, box := range boxes {
+		result.Detections[i] = Detection{
+			ClassID: box.ClassID,
+			Label:   box.Label,
+			Score:   box.Confidence,
+			Box:     NewBox(&box, result.Image),
+		}
+	}
+	return result
+}
+
+// Boxes returns the detections as bounding boxes in pixels.
+func (r *Result) Boxes() []utils.BoundingBox {
+	boxes := make([]utils.BoundingBox, len(r.Detections))
+	for i, d := range r.Detections {
+		boxes[i] = utils.BoundingBox{
+			Label:      d.Label,
+			ClassID:    d.ClassID,
+			Confidence: d.Score,
+			X1:         d.Box.XYXY[0],
+			Y1:         d.Box.XYXY[1],
+			X2:         d.Box.XYXY[2],
+			Y2:         d.Box.XYXY[3],
+		}
+	}
+	return boxes
+}
+
+// Info returns the name and version of the model.
+func (yo *YOLO) Info() ModelInfo {
+	return ModelInfo{Name: yo.name, Version: yo.version.String()}
+}
+
+// Timings returns the stage durations of the last prediction, summed over
+// every batch it ran. After an Ensemble prediction they are the model's
+// part of it.
+func (yo *YOLO) Timings() Timings {
+	return yo.timings
+}
+
+// PredictResult is Predict returning a Result with the image size, model
+// and timings, ready to be serialized.
+func (yo *YOLO) PredictResult(img image.Image, opts ...PredictOption) (*Result, error) {
+	boxes, err := yo.Predict(img, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := NewResult(boxes, img.Bounds().Size())
+	result.Model = yo.Info()
+	result.Timings = yo.timings
+	return result, nil
+}
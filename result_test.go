@@ -0,0 +1,97 @@
+package yolo
+
+import (
+	"encoding/json"
+	"image"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	models "github.com/zazamaza/yolo-object-detection-go/internal/models"
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+func TestNewResult(t *testing.T) {
+	boxes := []utils.BoundingBox{{Label: "person", ClassID: 0, Confidence: 0.91, X1: 100, Y1: 50, X2: 300, Y2: 650}}
+
+	result := NewResult(boxes, image.Pt(1280, 720))
+
+	expected := Box{
+		XYXY:  [4]float32{100, 50, 300, 650},
+		XYWH:  [4]float32{200, 350, 200, 600},
+		XYXYN: [4]float32{100.0 / 1280, 50.0 / 720, 300.0 / 1280, 650.0 / 720},
+		XYWHN: [4]float32{200.0 / 1280, 350.0 / 720, 200.0 / 1280, 600.0 / 720},
+	}
+	if result.SchemaVersion != ResultSchemaVersion || result.Image != (ImageSize{Width: 1280, Height: 720}) {
+		t.Errorf("unexpected header %+v", result)
+	}
+	if len(result.Detections) != 1 || result.Detections[0].Box != expected {
+		t.Fatalf("expected box %+v, got %+v", expected, result.Detections)
+	}
+	if d := result.Detections[0]; d.Label != "person" || d.ClassID != 0 || d.Score != 0.91 {
+		t.Errorf("unexpected detection %+v", d)
+	}
+}
+
+func TestResultJSONRoundTrip(t *testing.T) {
+	boxes := []utils.BoundingBox{
+		{Label: "person", ClassID: 0, Confidence: 0.91, X1: 100.25, Y1: 50.5, X2: 300.125, Y2: 650},
+		{Label: "traffic light", ClassID: 9, Confidence: 0.333, X1: 1, Y1: 2, X2: 3.3, Y2: 4.4},
+	}
+	result := NewResult(boxes, image.Pt(1280, 720))
+	result.Model = ModelInfo{Name: "yolo11n", Version: models.YOLOv11.String()}
+	result.Timings = Timings{Preprocess: 1234567 * time.Nanosecond, Inference: 8 * time.Millisecond, Postprocess: 3}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"schema_version":1`, `"name":"yolo11n"`, `"version":"yolo11"`, `"preprocess_ms":1.234567`, `"total_ms":9.23457`, `"xywhn":[`} {
+		if !strings.Contains(string(data), field) {
+			t.Errorf("expected %s in %s", field, data)
+		}
+	}
+
+	var decoded Result
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&decoded, result) {
+		t.Errorf("expected %+v, got %+v", result, &decoded)
+	}
+	if !reflect.DeepEqual(decoded.Boxes(), boxes) {
+		t.Errorf("expected boxes %+v, got %+v", boxes, decoded.Boxes())
+	}
+}
+
+func TestResultJSONEmpty(t *testing.T) {
+	data, err := json.Marshal(NewResult(nil, image.Point{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"detections":[]`) {
+		t.Errorf("expected an empty detections array, got %s", data)
+	}
+}
+
+func TestBoundingBoxJSONRoundTrip(t *testing.T) {
+	box := utils.BoundingBox{Label: "car", ClassID: 2, Confidence: 0.75, X1: 1.5, Y1: 2, X2: 30, Y2: 40.25}
+
+	data, err := json.Marshal(&box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"label":"car","class_id":2,"confidence":0.75,"x1":1.5,"y1":2,"x2":30,"y2":40.25}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+
+	var decoded utils.BoundingBox
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded != box {
+		t.Errorf("expected %+v, got %+v", box, decoded)
+	}
+}
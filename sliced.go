@@ -0,0 +1,130 @@
+package yolo
+
+import (
+	"fmt"
+	"image"
+
+	models "github.com/zazamaza/yolo-object-detection-go/internal/models"
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+type MatchMetric = utils.MatchMetric
+
+const (
+	MatchIoU = utils.MatchIoU
+	MatchIoS = utils.MatchIoS
+)
+
+// MergeStrategy decides how detections of neighbouring slices that
+// describe the same object are combined.
+type MergeStrategy int
+
+const (
+	// MergeGreedyNMM grows the best box to the union of the boxes matching it.
+	MergeGreedyNMM MergeStrategy = iota
+	// MergeNMM keeps growing the merged box while it matches more boxes.
+	MergeNMM
+	// MergeNMS keeps the best box and drops the boxes matching it.
+	MergeNMS
+)
+
+// SliceOptions configures sliced inference (SAHI): the image is tiled into
+// overlapping slices that are each letterboxed to the model input, so
+// small objects keep enough pixels to be detected.
+type SliceOptions struct {
+	SliceWidth         int
+	SliceHeight        int
+	OverlapWidthRatio  float64
+	OverlapHeightRatio float64
+	// FullImage adds a pass over the whole image to catch large objects
+	// that do not fit in a slice.
+	FullImage bool
+	// Merge, MatchMetric and MatchThreshold control how duplicates across
+	// slices are combined.
+	Merge          MergeStrategy
+	MatchMetric    MatchMetric
+	MatchThreshold float32
+	// AgnosticMerge merges boxes regardless of their class.
+	AgnosticMerge bool
+}
+
+func DefaultSliceOptions() SliceOptions {
+	return SliceOptions{
+		SliceWidth:         640,
+		SliceHeight:        640,
+		OverlapWidthRatio:  0.2,
+		OverlapHeightRatio: 0.2,
+		Merge:              MergeGreedyNMM,
+		MatchMetric:        MatchIoS,
+		MatchThreshold:     0.5,
+	}
+}
+
+func (s *SliceOptions) merger() utils.ISuppressor {
+	switch s.Merge {
+	case MergeNMS:
+		return &utils.GreedyNMS{Metric: s.MatchMetric}
+	case MergeNMM:
+		return &utils.NonMaximumMerging{Metric: s.MatchMetric}
+	default:
+		return &utils.NonMaximumMerging{Metric: s.MatchMetric, Greedy: true}
+	}
+}
+
+// PredictSliced runs the model on overlapping slices of img, batching the
+// slices through the engine, and merges the detections back into img
+// coordinates.
+func (yo *YOLO) PredictSliced(img image.Image, slicing SliceOptions, opts ...PredictOption) ([]utils.BoundingBox, error) {
+	options := yo.defaults
+	options.apply(opts)
+
+	img, offset, ok := cropROI(img, options.ROI)
+	if !ok {
+		return []utils.BoundingBox{}, nil
+	}
+
+	bounds := img.Bounds()
+	rects, err := utils.SliceRects(bounds.Dx(), bounds.Dy(),
+		slicing.SliceWidth, slicing.SliceHeight,
+		slicing.OverlapWidthRatio, slicing.OverlapHeightRatio,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error slicing image: %w", err)
+	}
+
+	tiles := make([]image.Image, 0, len(rects)+1)
+	for _, rect := range rects {
+		tiles = append(tiles, utils.SubImage(img, rect.Add(bounds.Min)))
+	}
+	if slicing.FullImage {
+		tiles = append(tiles, img)
+		rects = append(rects, image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	}
+
+	placements := make([]func(*utils.BoundingBox), len(rects))
+	for i, rect := range rects {
+		placements[i] = translation(rect.Min.Add(offset))
+	}
+
+	postProcessOptions := options.postProcessOptions()
+	batches, err := yo.predictBatch(tiles, postProcessOptions, placements)
+	if err != nil {
+		return nil, err
+	}
+
+	boxes := []utils.BoundingBox{}
+	for i, sliceBoxes := range batches {
+		for _, box := range sliceBoxes {
+			placements[i](&box)
+			boxes = append(boxes, box)
+		}
+	}
+
+	if !options.RawCandidates {
+		boxes = utils.SuppressBatched(slicing.merger(), boxes, slicing.MatchThreshold, slicing.AgnosticMerge)
+		// Merged boxes can move into a mask although none of their parts was.
+		boxes = utils.FilterExcluded(boxes, options.ExclusionMasks)
+		boxes = models.LimitDetections(boxes, postProcessOptions)
+	}
+	return boxes, nil
+}
@@ -0,0 +1,73 @@
+package yolo
+
+import (
+	"image"
+	"reflect"
+	"testing"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+func TestSliceOptionsMerger(t *testing.T) {
+	options := DefaultSliceOptions()
+
+	merger, ok := options.merger().(*utils.NonMaximumMerging)
+	if !ok || !merger.Greedy || merger.Metric != MatchIoS {
+		t.Errorf("expected greedy NMM on IoS by default, got %+v", options.merger())
+	}
+
+	options.Merge = MergeNMM
+	if merger, ok := options.merger().(*utils.NonMaximumMerging); !ok || merger.Greedy {
+		t.Errorf("expected NMM, got %+v", options.merger())
+	}
+
+	options.Merge = MergeNMS
+	options.MatchMetric = MatchIoU
+	if nms, ok := options.merger().(*utils.GreedyNMS); !ok || nms.Metric != MatchIoU {
+		t.Errorf("expected NMS on IoU, got %+v", options.merger())
+	}
+}
+
+func TestPredictSliced(t *testing.T) {
+	objects := []utils.BoundingBox{
+		{Label: "car", ClassID: 1, Confidence: 0.95, X1: 30, Y1: 15, X2: 170, Y2: 85},
+		// Crosses the seam of the two slices.
+		{Label: "person", Confidence: 0.9, X1: 110, Y1: 30, X2: 130, Y2: 60},
+		{Label: "person", Confidence: 0.8, X1: 30, Y1: 20, X2: 50, Y2: 40},
+	}
+	preProcess := &fakePreProcess{}
+	model := fakeModel(preProcess, &fakePostProcess{preProcess: preProcess, objects: objects}, 2)
+
+	slicing := DefaultSliceOptions()
+	slicing.SliceWidth, slicing.SliceHeight = 100, 80
+	slicing.FullImage = true
+
+	img := image.NewNRGBA(image.Rect(0, 0, 200, 100))
+	boxes, err := model.PredictSliced(img, slicing, WithROI(image.Rect(20, 10, 180, 90)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedTiles := []image.Rectangle{image.Rect(20, 10, 120, 90), image.Rect(80, 10, 180, 90), image.Rect(20, 10, 180, 90)}
+	if !reflect.DeepEqual(preProcess.bounds, expectedTiles) {
+		t.Errorf("expected slices %v, got %v", expectedTiles, preProcess.bounds)
+	}
+	if !reflect.DeepEqual(boxes, objects) {
+		t.Errorf("expected %+v, got %+v", objects, boxes)
+	}
+}
+
+func TestPredictSlicedInvalidOptions(t *testing.T) {
+	model := &YOLO{}
+	img := image.NewNRGBA(image.Rect(0, 0, 4000, 3000))
+
+	for _, slicing := range []SliceOptions{
+		{},
+		{SliceWidth: 640, SliceHeight: 640, OverlapWidthRatio: 1},
+		{SliceWidth: 640, SliceHeight: 640, OverlapHeightRatio: -0.2},
+	} {
+		if _, err := model.PredictSliced(img, slicing); err == nil {
+			t.Errorf("expected an error for %+v", slicing)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package tracker
+
+import (
+	"math"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// appearance is the gallery of recent ReID features of a track.
+type appearance struct {
+	gallery [][]float32
+	next    int
+}
+
+// add stores a feature, replacing the oldest once the gallery holds
+// budget features.
+func (a *appearance) add(feature []float32, budget int) {
+	if len(a.gallery) < max(budget, 1) {
+		a.gallery = append(a.gallery, feature)
+		return
+	}
+	a.gallery[a.next] = feature
+	a.next = (a.next + 1) % len(a.gallery)
+}
+
+// distance returns the smallest cosine distance between feature and the
+// gallery, or 1 without features to compare.
+func (a *appearance) distance(feature []float32) float32 {
+	if a == nil || len(a.gallery) == 0 || feature == nil {
+		return 1
+	}
+
+	distance := float32(math.Inf(1))
+	for _, stored := range a.gallery {
+		distance = min(distance, cosineDistance(stored, feature))
+	}
+	return distance
+}
+
+// cosineDistance returns 1 - cos of two L2 normalized features.
+func cosineDistance(a, b []float32) float32 {
+	var dot float32
+	for i := range min(len(a), len(b)) {
+		dot += a[i] * b[i]
+	}
+	return 1 - dot
+}
+
+// normalize scales feature to unit length in place.
+func normalize(feature []float32) {
+	var sum float64
+	for _, v := range feature {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return
+	}
+	norm := float32(1 / math.Sqrt(sum))
+	for i := range feature {
+		feature[i] *= norm
+	}
+}
+
+// fusedCost combines the IoU and appearance costs as in BoT-SORT: the
+// halved cosine distance is only trusted for nearby pairs with similar
+// appearance, and the lower of the two costs is used.
+func fusedCost(tracks []*Track, boxes []utils.BoundingBox, features [][]float32,
+	classAware, fuseScore bool, appearanceThreshold, proximityThreshold float32,
+) [][]float64 {
+
+	iou := iouCost(tracks, boxes, classAware, false)
+	cost := iou
+	if fuseScore {
+		cost = iouCost(tracks, boxes, classAware, true)
+	}
+
+	for i, track := range tracks {
+		for j := range boxes {
+			if iou[i][j] >= forbidden || iou[i][j] > float64(proximityThreshold) {
+				continue
+			}
+			distance := track.appearance.distance(features[j]) / 2
+			if distance > appearanceThreshold {
+				continue
+			}
+			cost[i][j] = min(cost[i][j], float64(distance))
+		}
+	}
+	return cost
+}
@@ -0,0 +1,65 @@
+package tracker
+
+import (
+	"math"
+	"testing"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+func TestAppearanceGallery(t *testing.T) {
+	var missing *appearance
+	if distance := missing.distance([]float32{1, 0}); distance != 1 {
+		t.Errorf("expected distance 1 without a gallery, got %f", distance)
+	}
+
+	a := &appearance{}
+	a.add([]float32{1, 0}, 2)
+	a.add([]float32{0, 1}, 2)
+	if distance := a.distance([]float32{1, 0}); distance != 0 {
+		t.Errorf("expected the closest feature to match, got %f", distance)
+	}
+
+	// The oldest feature is replaced once the budget is reached.
+	a.add([]float32{0, -1}, 2)
+	if len(a.gallery) != 2 {
+		t.Fatalf("expected gallery of 2, got %d", len(a.gallery))
+	}
+	if distance := a.distance([]float32{1, 0}); distance != 1 {
+		t.Errorf("expected the oldest feature to be dropped, got %f", distance)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	feature := []float32{3, 4}
+	normalize(feature)
+	if math.Abs(float64(feature[0])-0.6) > 1e-6 || math.Abs(float64(feature[1])-0.8) > 1e-6 {
+		t.Errorf("expected (0.6, 0.8), got %v", feature)
+	}
+}
+
+func TestFusedCost(t *testing.T) {
+	track := newTrack(1, utils.BoundingBox{X1: 0, Y1: 0, X2: 100, Y2: 100})
+	track.appearance = &appearance{}
+	track.appearance.add([]float32{1, 0}, 1)
+
+	boxes := []utils.BoundingBox{
+		{X1: 10, Y1: 0, X2: 110, Y2: 100, Confidence: 1}, // near, same look
+		{X1: 10, Y1: 0, X2: 110, Y2: 100, Confidence: 1}, // near, other look
+		{X1: 80, Y1: 0, X2: 180, Y2: 100, Confidence: 1}, // far, same look
+	}
+	features := [][]float32{{1, 0}, {0, 1}, {1, 0}}
+
+	cost := fusedCost([]*Track{track}, boxes, features, false, false, 0.25, 0.5)
+	iou := iouCost([]*Track{track}, boxes, false, false)
+
+	if cost[0][0] != 0 {
+		t.Errorf("expected appearance to win for a near look-alike, got %f", cost[0][0])
+	}
+	if cost[0][1] != iou[0][1] {
+		t.Errorf("expected IoU cost for a different look, got %f", cost[0][1])
+	}
+	if cost[0][2] != iou[0][2] {
+		t.Errorf("expected IoU cost beyond the proximity threshold, got %f", cost[0][2])
+	}
+}
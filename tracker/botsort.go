@@ -0,0 +1,91 @@
+package tracker
+
+import (
+	"image"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// BoTSORT extends ByteTrack with appearance and camera motion: confident
+// detections are embedded by a ReID model and matched against per-track
+// feature galleries, and tracks are moved with the estimated camera motion
+// before association. It is not safe for concurrent use.
+type BoTSORT struct {
+	ByteTrack
+	Embedder IEmbedder
+	// CameraMotion compensates tracks for camera movement; nil disables it.
+	CameraMotion *CameraMotion
+	// AppearanceThreshold is the largest halved cosine distance at which
+	// appearance is trusted.
+	AppearanceThreshold float32
+	// ProximityThreshold is the largest 1 - IoU cost at which appearance
+	// is considered, so distant look-alikes never match.
+	ProximityThreshold float32
+	// GallerySize is the number of recent features kept per track.
+	GallerySize int
+}
+
+func NewBoTSORT(embedder IEmbedder) *BoTSORT {
+	return &BoTSORT{
+		ByteTrack:           *NewByteTrack(),
+		Embedder:            embedder,
+		CameraMotion:        NewCameraMotion(),
+		AppearanceThreshold: 0.25,
+		ProximityThreshold:  0.5,
+		GallerySize:         30,
+	}
+}
+
+// Update consumes the next frame and its detections and returns the live
+// tracks, including tentative and lost ones.
+func (b *BoTSORT) Update(frame image.Image, boxes []utils.BoundingBox) ([]Track, error) {
+	// Only the high score boxes take part in appearance matching.
+	var confident []utils.BoundingBox
+	var indices []int
+	for i, box := range boxes {
+		if box.Confidence >= b.HighThreshold {
+			confident = append(confident, box)
+			indices = append(indices, i)
+		}
+	}
+
+	features := make([][]float32, len(boxes))
+	if b.Embedder != nil && len(confident) > 0 {
+		embedded, err := b.Embedder.Embed(frame, confident)
+		if err != nil {
+			return nil, err
+		}
+		for i, index := range indices {
+			features[index] = embedded[i]
+		}
+	}
+
+	motion := IdentityAffine()
+	if b.CameraMotion != nil {
+		motion = b.CameraMotion.Estimate(frame, boxes)
+	}
+
+	hooks := associationHooks{
+		cost: func(tracks []*Track, indices []int) [][]float64 {
+			return fusedCost(tracks, pick(boxes, indices), pick(features, indices),
+				b.ClassAware, b.FuseScore, b.AppearanceThreshold, b.ProximityThreshold)
+		},
+		matched: func(track *Track, index int) {
+			if features[index] == nil {
+				return
+			}
+			if track.appearance == nil {
+				track.appearance = &appearance{}
+			}
+			track.appearance.add(features[index], b.GallerySize)
+		},
+	}
+	if motion != IdentityAffine() {
+		hooks.predicted = func(tracks []*Track) {
+			for _, track := range tracks {
+				track.compensate(motion)
+			}
+		}
+	}
+	return b.step(boxes, hooks), nil
+}
@@ -0,0 +1,74 @@
+package tracker
+
+import (
+	"image"
+	"testing"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// labelEmbedder gives every label its own orthogonal feature.
+type labelEmbedder map[string][]float32
+
+func (e labelEmbedder) Embed(frame image.Image, boxes []utils.BoundingBox) ([][]float32, error) {
+	features := make([][]float32, len(boxes))
+	for i, box := range boxes {
+		features[i] = e[box.Label]
+	}
+	return features, nil
+}
+
+func wide(label string, x float32) utils.BoundingBox {
+	return utils.BoundingBox{Label: label, Confidence: 1, X1: x, Y1: 0, X2: x + 100, Y2: 100}
+}
+
+func TestBoTSORTAppearanceKeepsIdentity(t *testing.T) {
+	frame := image.NewGray(image.Rect(0, 0, 320, 240))
+	first := []utils.BoundingBox{wide("a", 0), wide("b", 30)}
+	// The objects overlap and swap sides, so IoU alone prefers swapping
+	// their IDs.
+	second := []utils.BoundingBox{wide("a", 20), wide("b", 10)}
+
+	bt := NewByteTrack()
+	bt.Update(first)
+	tracks := bt.Update(second)
+	if track := trackByID(tracks, 1); track == nil || track.Box.Label != "b" {
+		t.Fatalf("expected IoU association to swap identities, got %+v", tracks)
+	}
+
+	botsort := NewBoTSORT(labelEmbedder{"a": {1, 0}, "b": {0, 1}})
+	botsort.CameraMotion = nil
+	if _, err := botsort.Update(frame, first); err != nil {
+		t.Fatal(err)
+	}
+	tracks, err := botsort.Update(frame, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if track := trackByID(tracks, 1); track == nil || track.Box.Label != "a" {
+		t.Errorf("expected track 1 to stay on a, got %+v", tracks)
+	}
+	if track := trackByID(tracks, 2); track == nil || track.Box.Label != "b" {
+		t.Errorf("expected track 2 to stay on b, got %+v", tracks)
+	}
+}
+
+func TestBoTSORTCameraMotion(t *testing.T) {
+	botsort := NewBoTSORT(labelEmbedder{"a": {1}})
+	// A small object keeps still in the scene while the camera pans, so it
+	// moves by the pan in the image.
+	box := func(x float32) utils.BoundingBox {
+		return utils.BoundingBox{Label: "a", Confidence: 1, X1: x, Y1: 100, X2: x + 10, Y2: 120}
+	}
+
+	if _, err := botsort.Update(texture(320, 240, 0, 0), []utils.BoundingBox{box(100)}); err != nil {
+		t.Fatal(err)
+	}
+	tracks, err := botsort.Update(texture(320, 240, 9, 0), []utils.BoundingBox{box(109)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracks) != 1 || tracks[0].ID != 1 || tracks[0].State != Confirmed {
+		t.Errorf("expected the compensated track to match, got %+v", tracks)
+	}
+}
@@ -0,0 +1,187 @@
+package tracker
+
+import (
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// ByteTrack associates every detection box, not only the confident ones:
+// high score boxes are matched first and the remaining tracks are then
+// matched to low score boxes, recovering occluded objects. It is not safe
+// for concurrent use.
+type ByteTrack struct {
+	// HighThreshold splits detections into the first and second
+	// association.
+	HighThreshold float32
+	// LowThreshold drops detections entirely.
+	LowThreshold float32
+	// NewTrackThreshold is the minimum score to start a track.
+	NewTrackThreshold float32
+	// MatchThreshold, SecondMatchThreshold and UnconfirmedMatchThreshold
+	// are the maximum 1 - IoU costs of the first association, the low
+	// score association and the tentative track association.
+	MatchThreshold            float32
+	SecondMatchThreshold      float32
+	UnconfirmedMatchThreshold float32
+	// MaxLost is the number of frames a lost track is kept for.
+	MaxLost int
+	// FuseScore weights the IoU of the first association by the detection
+	// score.
+	FuseScore bool
+	// ClassAware only matches detections of the track's class.
+	ClassAware bool
+
+	tracks []*Track
+	nextID int
+	frame  int
+}
+
+// NewByteTrack returns the defaults of the reference implementation for
+// 30 FPS video.
+func NewByteTrack() *ByteTrack {
+	return &ByteTrack{
+		HighThreshold:             0.5,
+		LowThreshold:              0.1,
+		NewTrackThreshold:         0.6,
+		MatchThreshold:            0.8,
+		SecondMatchThreshold:      0.5,
+		UnconfirmedMatchThreshold: 0.7,
+		MaxLost:                   30,
+		FuseScore:                 true,
+	}
+}
+
+// associationHooks customise the association steps of ByteTrack for
+// trackers that add appearance or camera motion.
+type associationHooks struct {
+	// predicted runs after every track was moved by its motion model.
+	predicted func(tracks []*Track)
+	// cost replaces the IoU cost of the high score associations. The
+	// boxes are given by their index in the frame.
+	cost func(tracks []*Track, indices []int) [][]float64
+	// matched runs when a track is updated with, or started from, the
+	// box at index.
+	matched func(track *Track, index int)
+}
+
+// Update consumes the detections of the next frame and returns the live
+// tracks, including tentative and lost ones.
+func (bt *ByteTrack) Update(boxes []utils.BoundingBox) []Track {
+	return bt.step(boxes, associationHooks{})
+}
+
+func (bt *ByteTrack) step(boxes []utils.BoundingBox, hooks associationHooks) []Track {
+	bt.frame++
+
+	var high, low []int
+	for i, box := range boxes {
+		switch {
+		case box.Confidence >= bt.HighThreshold:
+			high = append(high, i)
+		case box.Confidence > bt.LowThreshold:
+			low = append(low, i)
+		}
+	}
+
+	highCost := func(tracks []*Track, indices []int) [][]float64 {
+		if hooks.cost != nil {
+			return hooks.cost(tracks, indices)
+		}
+		return iouCost(tracks, pick(boxes, indices), bt.ClassAware, bt.FuseScore)
+	}
+	update := func(track *Track, index int) {
+		track.update(boxes[index])
+		if hooks.matched != nil {
+			hooks.matched(track, index)
+		}
+	}
+
+	var pool, unconfirmed []*Track
+	for _, track := range bt.tracks {
+		track.predict()
+		if track.State == Tentative {
+			unconfirmed = append(unconfirmed, track)
+		} else {
+			pool = append(pool, track)
+		}
+	}
+	if hooks.predicted != nil {
+		hooks.predicted(bt.tracks)
+	}
+
+	// First association: confirmed and lost tracks with high score boxes.
+	matches, unmatchedTracks, unmatchedHigh := assign(highCost(pool, high), len(high), float64(bt.MatchThreshold))
+	for _, match := range matches {
+		update(pool[match[0]], high[match[1]])
+		pool[match[0]].State = Confirmed
+	}
+
+	// Second association: the remaining tracked tracks with low score
+	// boxes. Lost tracks are not revived by low score boxes.
+	var remaining []*Track
+	for _, i := range unmatchedTracks {
+		if pool[i].State == Confirmed {
+			remaining = append(remaining, pool[i])
+		} else {
+			bt.expire(pool[i])
+		}
+	}
+	cost := iouCost(remaining, pick(boxes, low), bt.ClassAware, false)
+	matches, unmatchedTracks, _ = assign(cost, len(low), float64(bt.SecondMatchThreshold))
+	for _, match := range matches {
+		update(remaining[match[0]], low[match[1]])
+	}
+	for _, i := range unmatchedTracks {
+		remaining[i].State = Lost
+		bt.expire(remaining[i])
+	}
+
+	// Tentative tracks only get the high score boxes left over.
+	leftover := make([]int, len(unmatchedHigh))
+	for i, j := range unmatchedHigh {
+		leftover[i] = high[j]
+	}
+	matches, unmatchedTracks, unmatchedLeftover := assign(highCost(unconfirmed, leftover), len(leftover), float64(bt.UnconfirmedMatchThreshold))
+	for _, match := range matches {
+		update(unconfirmed[match[0]], leftover[match[1]])
+		unconfirmed[match[0]].State = Confirmed
+	}
+	for _, i := range unmatchedTracks {
+		unconfirmed[i].State = Removed
+	}
+
+	for _, j := range unmatchedLeftover {
+		index := leftover[j]
+		if boxes[index].Confidence < bt.NewTrackThreshold {
+			continue
+		}
+		bt.nextID++
+		track := newTrack(bt.nextID, boxes[index])
+		// Tracks of the first frame are confirmed straight away.
+		if bt.frame == 1 {
+			track.State = Confirmed
+		}
+		if hooks.matched != nil {
+			hooks.matched(track, index)
+		}
+		bt.tracks = append(bt.tracks, track)
+	}
+
+	bt.tracks = prune(bt.tracks)
+	return snapshot(bt.tracks)
+}
+
+// expire removes a lost track once it has been missing for MaxLost frames.
+func (bt *ByteTrack) expire(track *Track) {
+	if track.TimeSinceUpdate > bt.MaxLost {
+		track.State = Removed
+	}
+}
+
+// pick returns the items at indices.
+func pick[T any](items []T, indices []int) []T {
+	picked := make([]T, len(indices))
+	for i, index := range indices {
+		picked[i] = items[index]
+	}
+	return picked
+}
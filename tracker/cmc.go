@@ -0,0 +1,334 @@
+package tracker
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"slices"
+
+	"github.com/disintegration/imaging"
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// Affine maps points of the previous frame to the current frame:
+// x' = a[0][0]x + a[0][1]y + a[0][2], y' = a[1][0]x + a[1][1]y + a[1][2].
+type Affine [2][3]float64
+
+func IdentityAffine() Affine {
+	return Affine{{1, 0, 0}, {0, 1, 0}}
+}
+
+// Apply maps a point of the previous frame into the current frame.
+func (a Affine) Apply(x, y float64) (float64, float64) {
+	return a[0][0]*x + a[0][1]*y + a[0][2], a[1][0]*x + a[1][1]*y + a[1][2]
+}
+
+// scale returns the isotropic scale of the linear part.
+func (a Affine) scale() float64 {
+	return math.Sqrt(abs(a[0][0]*a[1][1] - a[0][1]*a[1][0]))
+}
+
+// CameraMotion estimates the global motion between consecutive frames of
+// a moving camera. Corner features of the background are tracked by block
+// matching and a robust affine transform is fitted to them. It is not safe
+// for concurrent use.
+type CameraMotion struct {
+	// MaxWidth downscales frames before estimation.
+	MaxWidth int
+	// MaxFeatures is the number of background corners tracked.
+	MaxFeatures int
+	// SearchRadius is the largest motion found, in downscaled pixels.
+	SearchRadius int
+	// InlierThreshold is the RANSAC reprojection error, in downscaled
+	// pixels.
+	InlierThreshold float64
+
+	previous *image.Gray
+	// previousBoxes are the boxes of the previous frame, downscaled like it.
+	previousBoxes []image.Rectangle
+}
+
+func NewCameraMotion() *CameraMotion {
+	return &CameraMotion{
+		MaxWidth:        320,
+		MaxFeatures:     200,
+		SearchRadius:    12,
+		InlierThreshold: 1.5,
+	}
+}
+
+const (
+	// cmcPatchRadius is the half size of the matched patches.
+	cmcPatchRadius = 4
+	// cmcMinInliers is the smallest support accepted for a transform.
+	cmcMinInliers = 6
+	cmcIterations = 200
+)
+
+// Estimate returns the motion from the previous frame to frame, ignoring
+// features inside the boxes detected in the previous frame, which belong
+// to moving objects. boxes are the detections in frame. The first frame
+// and frames without enough background texture give the identity.
+func (c *CameraMotion) Estimate(frame image.Image, boxes []utils.BoundingBox) Affine {
+	bounds := frame.Bounds()
+	scale := 1.0
+	if c.MaxWidth > 0 && bounds.Dx() > c.MaxWidth {
+		scale = float64(c.MaxWidth) / float64(bounds.Dx())
+		frame = imaging.Resize(frame, c.MaxWidth, 0, imaging.Box)
+	}
+	current := toGray(frame)
+
+	scaled := make([]image.Rectangle, len(boxes))
+	for i, box := range boxes {
+		scaled[i] = image.Rect(
+			int(float64(box.X1)*scale), int(float64(box.Y1)*scale),
+			int(math.Ceil(float64(box.X2)*scale)), int(math.Ceil(float64(box.Y2)*scale)),
+		)
+	}
+
+	// Features are picked in the previous frame, so they are masked with
+	// the objects where they were then.
+	previous, masked := c.previous, c.previousBoxes
+	c.previous, c.previousBoxes = current, scaled
+	if previous == nil || previous.Rect != current.Rect {
+		return IdentityAffine()
+	}
+
+	var from, to [][2]float64
+	for _, pt := range c.features(previous, masked) {
+		if matched, ok := c.match(previous, current, pt); ok {
+			from = append(from, [2]float64{float64(pt.X), float64(pt.Y)})
+			to = append(to, matched)
+		}
+	}
+
+	motion, ok := c.fit(from, to)
+	if !ok {
+		return IdentityAffine()
+	}
+	// Back to full resolution: only the translation depends on the scale.
+	motion[0][2] /= scale
+	motion[1][2] /= scale
+	return motion
+}
+
+// features returns the strongest corners of img outside the masked
+// rectangles, scored by the smaller eigenvalue of the structure tensor.
+func (c *CameraMotion) features(img *image.Gray, masked []image.Rectangle) []image.Point {
+	type corner struct {
+		pt    image.Point
+		score float64
+	}
+
+	margin := c.SearchRadius + cmcPatchRadius + 1
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	step := max(int(math.Sqrt(float64(width*height)/float64(max(c.MaxFeatures, 1)*4))), 4)
+
+	var corners []corner
+	for y := margin; y < height-margin; y += step {
+		for x := margin; x < width-margin; x += step {
+			pt := image.Pt(x, y)
+			if slices.ContainsFunc(masked, pt.In) {
+				continue
+			}
+
+			var gxx, gyy, gxy float64
+			for dy := -cmcPatchRadius; dy <= cmcPatchRadius; dy++ {
+				for dx := -cmcPatchRadius; dx <= cmcPatchRadius; dx++ {
+					gx := float64(gray(img, x+dx+1, y+dy)) - float64(gray(img, x+dx-1, y+dy))
+					gy := float64(gray(img, x+dx, y+dy+1)) - float64(gray(img, x+dx, y+dy-1))
+					gxx += gx * gx
+					gyy += gy * gy
+					gxy += gx * gy
+				}
+			}
+			score := (gxx + gyy - math.Sqrt((gxx-gyy)*(gxx-gyy)+4*gxy*gxy)) / 2
+			if score > 1e3 {
+				corners = append(corners, corner{pt, score})
+			}
+		}
+	}
+
+	slices.SortFunc(corners, func(a, b corner) int {
+		switch {
+		case a.score > b.score:
+			return -1
+		case a.score < b.score:
+			return 1
+		}
+		return 0
+	})
+	points := make([]image.Point, 0, min(len(corners), c.MaxFeatures))
+	for _, corner := range corners[:min(len(corners), c.MaxFeatures)] {
+		points = append(points, corner.pt)
+	}
+	return points
+}
+
+// match finds the patch around pt of previous in current by minimising
+// the sum of absolute differences, refined to sub-pixel precision. Matches
+// on the edge of the search window are rejected.
+func (c *CameraMotion) match(previous, current *image.Gray, pt image.Point) ([2]float64, bool) {
+	radius := c.SearchRadius
+	size := 2*radius + 1
+	costs := make([]int, size*size)
+
+	best, bestX, bestY := math.MaxInt, 0, 0
+	for sy := -radius; sy <= radius; sy++ {
+		for sx := -radius; sx <= radius; sx++ {
+			sad := 0
+			for dy := -cmcPatchRadius; dy <= cmcPatchRadius; dy++ {
+				for dx := -cmcPatchRadius; dx <= cmcPatchRadius; dx++ {
+					diff := int(gray(previous, pt.X+dx, pt.Y+dy)) - int(gray(current, pt.X+sx+dx, pt.Y+sy+dy))
+					sad += max(diff, -diff)
+				}
+			}
+			costs[(sy+radius)*size+sx+radius] = sad
+			if sad < best {
+				best, bestX, bestY = sad, sx, sy
+			}
+		}
+	}
+	if bestX == -radius || bestX == radius || bestY == -radius || bestY == radius {
+		return [2]float64{}, false
+	}
+
+	at := func(x, y int) float64 { return float64(costs[(y+radius)*size+x+radius]) }
+	offsetX := parabolaPeak(at(bestX-1, bestY), at(bestX, bestY), at(bestX+1, bestY))
+	offsetY := parabolaPeak(at(bestX, bestY-1), at(bestX, bestY), at(bestX, bestY+1))
+	return [2]float64{
+		float64(pt.X+bestX) + offsetX,
+		float64(pt.Y+bestY) + offsetY,
+	}, true
+}
+
+// parabolaPeak returns the offset of the minimum of the parabola through
+// three equally spaced costs, relative to the middle one.
+func parabolaPeak(left, middle, right float64) float64 {
+	denominator := left - 2*middle + right
+	if denominator <= 0 {
+		return 0
+	}
+	return min(max((left-right)/(2*denominator), -0.5), 0.5)
+}
+
+// fit estimates the affine transform from matched points with RANSAC and
+// refines it by least squares on the inliers.
+func (c *CameraMotion) fit(from, to [][2]float64) (Affine, bool) {
+	if len(from) < cmcMinInliers {
+		return Affine{}, false
+	}
+
+	// A fixed seed keeps the estimate reproducible for a given frame pair.
+	random := rand.New(rand.NewSource(1))
+	var best []int
+	for iteration := 0; iteration < cmcIterations; iteration++ {
+		sample := random.Perm(len(from))[:3]
+		candidate, ok := solveAffine(from, to, sample)
+		if !ok {
+			continue
+		}
+		inliers := c.inliers(candidate, from, to)
+		if len(inliers) > len(best) {
+			best = inliers
+		}
+	}
+	if len(best) < cmcMinInliers {
+		return Affine{}, false
+	}
+	return solveAffine(from, to, best)
+}
+
+func (c *CameraMotion) inliers(motion Affine, from, to [][2]float64) []int {
+	var inliers []int
+	for i := range from {
+		x, y := motion.Apply(from[i][0], from[i][1])
+		if math.Hypot(x-to[i][0], y-to[i][1]) <= c.InlierThreshold {
+			inliers = append(inliers, i)
+		}
+	}
+	return inliers
+}
+
+// solveAffine fits the affine transform mapping from to to over the given
+// indices by least squares; three points give the exact solution.
+func solveAffine(from, to [][2]float64, indices []int) (Affine, bool) {
+	var normal [3][3]float64
+	var rhs [2][3]float64
+	for _, i := range indices {
+		p := [3]float64{from[i][0], from[i][1], 1}
+		for r := 0; r < 3; r++ {
+			for c := 0; c < 3; c++ {
+				normal[r][c] += p[r] * p[c]
+			}
+			rhs[0][r] += p[r] * to[i][0]
+			rhs[1][r] += p[r] * to[i][1]
+		}
+	}
+
+	var motion Affine
+	for row := 0; row < 2; row++ {
+		solution, ok := solve3(normal, rhs[row])
+		if !ok {
+			return Affine{}, false
+		}
+		motion[row] = solution
+	}
+	return motion, true
+}
+
+// solve3 solves the 3x3 system m x = b by Gaussian elimination with
+// partial pivoting.
+func solve3(m [3][3]float64, b [3]float64) ([3]float64, bool) {
+	for col := 0; col < 3; col++ {
+		pivot := col
+		for row := col + 1; row < 3; row++ {
+			if abs(m[row][col]) > abs(m[pivot][col]) {
+				pivot = row
+			}
+		}
+		if abs(m[pivot][col]) < 1e-9 {
+			return [3]float64{}, false
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		b[col], b[pivot] = b[pivot], b[col]
+
+		for row := col + 1; row < 3; row++ {
+			factor := m[row][col] / m[col][col]
+			for j := col; j < 3; j++ {
+				m[row][j] -= factor * m[col][j]
+			}
+			b[row] -= factor * b[col]
+		}
+	}
+
+	var x [3]float64
+	for row := 2; row >= 0; row-- {
+		sum := b[row]
+		for j := row + 1; j < 3; j++ {
+			sum -= m[row][j] * x[j]
+		}
+		x[row] = sum / m[row][row]
+	}
+	return x, true
+}
+
+// toGray converts img to 8-bit luminance with its origin at 0, 0.
+func toGray(img image.Image) *image.Gray {
+	bounds := img.Bounds()
+	result := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			result.Pix[y*result.Stride+x] = uint8((19595*r + 38470*g + 7471*b + 1<<15) >> 24)
+		}
+	}
+	return result
+}
+
+// gray returns the pixel at x, y, clamping to the image edges.
+func gray(img *image.Gray, x, y int) uint8 {
+	x = min(max(x, 0), img.Rect.Dx()-1)
+	y = min(max(y, 0), img.Rect.Dy()-1)
+	return img.Pix[y*img.Stride+x]
+}
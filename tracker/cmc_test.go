@@ -0,0 +1,128 @@
+package tracker
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// texture returns a blocky random image shifted by dx, dy.
+func texture(width, height, dx, dy int) *image.Gray {
+	random := rand.New(rand.NewSource(7))
+	const block = 4
+	cells := make([]uint8, (width/block+8)*(height/block+8))
+	for i := range cells {
+		cells[i] = uint8(random.Intn(256))
+	}
+
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			sx, sy := x-dx+16, y-dy+16
+			img.SetGray(x, y, color.Gray{Y: cells[(sy/block)*(width/block+8)+sx/block]})
+		}
+	}
+	return img
+}
+
+func TestCameraMotionTranslation(t *testing.T) {
+	c := NewCameraMotion()
+	if motion := c.Estimate(texture(320, 240, 0, 0), nil); motion != IdentityAffine() {
+		t.Errorf("expected identity on the first frame, got %v", motion)
+	}
+
+	motion := c.Estimate(texture(320, 240, 6, -3), nil)
+	if math.Abs(motion[0][2]-6) > 0.25 || math.Abs(motion[1][2]+3) > 0.25 {
+		t.Errorf("expected translation (6, -3), got %v", motion)
+	}
+	if math.Abs(motion[0][0]-1) > 0.01 || math.Abs(motion[1][1]-1) > 0.01 {
+		t.Errorf("expected no scaling, got %v", motion)
+	}
+}
+
+func TestCameraMotionDownscaled(t *testing.T) {
+	c := NewCameraMotion()
+	c.MaxWidth = 160
+	c.Estimate(texture(320, 240, 0, 0), nil)
+
+	motion := c.Estimate(texture(320, 240, 8, 4), nil)
+	if math.Abs(motion[0][2]-8) > 1 || math.Abs(motion[1][2]-4) > 1 {
+		t.Errorf("expected translation (8, 4) in full resolution, got %v", motion)
+	}
+}
+
+// movingObject returns a flat frame with a textured object inside rect,
+// inset so that corners outside rect do not see it.
+func movingObject(rect image.Rectangle) *image.Gray {
+	rect = rect.Inset(6)
+	object := texture(rect.Dx(), rect.Dy(), 0, 0)
+	img := image.NewGray(image.Rect(0, 0, 320, 240))
+	for y := range img.Rect.Dy() {
+		for x := range img.Rect.Dx() {
+			img.SetGray(x, y, color.Gray{Y: 128})
+			if pt := image.Pt(x, y); pt.In(rect) {
+				img.SetGray(x, y, object.GrayAt(x-rect.Min.X, y-rect.Min.Y))
+			}
+		}
+	}
+	return img
+}
+
+func TestCameraMotionMasksPreviousBoxes(t *testing.T) {
+	// The object moves diagonally on a static, textureless background: none of
+	// its features may be picked where it was in the previous frame.
+	before := image.Rect(100, 80, 180, 160)
+	after := before.Add(image.Pt(11, 11))
+	box := func(r image.Rectangle) []utils.BoundingBox {
+		return []utils.BoundingBox{{X1: float32(r.Min.X), Y1: float32(r.Min.Y), X2: float32(r.Max.X), Y2: float32(r.Max.Y)}}
+	}
+
+	c := NewCameraMotion()
+	c.Estimate(movingObject(before), box(before))
+	if motion := c.Estimate(movingObject(after), box(after)); motion != IdentityAffine() {
+		t.Errorf("expected identity for a moving object on a static background, got %v", motion)
+	}
+}
+
+func TestSolveAffine(t *testing.T) {
+	expected := Affine{{1.1, -0.2, 5}, {0.3, 0.9, -2}}
+	from := [][2]float64{{0, 0}, {10, 0}, {0, 10}, {7, 3}}
+	to := make([][2]float64, len(from))
+	for i, pt := range from {
+		to[i][0], to[i][1] = expected.Apply(pt[0], pt[1])
+	}
+
+	motion, ok := solveAffine(from, to, []int{0, 1, 2, 3})
+	if !ok {
+		t.Fatalf("expected a solution")
+	}
+	for r := range motion {
+		for c := range motion[r] {
+			if math.Abs(motion[r][c]-expected[r][c]) > 1e-9 {
+				t.Errorf("expected %v, got %v", expected, motion)
+			}
+		}
+	}
+
+	if _, ok := solveAffine(from, to, []int{0, 0, 0}); ok {
+		t.Errorf("expected degenerate points to fail")
+	}
+}
+
+func TestTrackCompensate(t *testing.T) {
+	track := newTrack(1, utils.BoundingBox{X1: 10, Y1: 20, X2: 30, Y2: 60})
+	track.compensate(Affine{{2, 0, 5}, {0, 2, -5}})
+
+	expected := utils.BoundingBox{X1: 25, Y1: 35, X2: 65, Y2: 115}
+	box := track.Box
+	for _, pair := range [][2]float32{{box.X1, expected.X1}, {box.Y1, expected.Y1}, {box.X2, expected.X2}, {box.Y2, expected.Y2}} {
+		if math.Abs(float64(pair[0]-pair[1])) > 1e-3 {
+			t.Errorf("expected %+v, got %+v", expected, box)
+			break
+		}
+	}
+}
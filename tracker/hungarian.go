@@ -0,0 +1,128 @@
+package tracker
+
+import "math"
+
+// assign solves the linear assignment problem for a cost matrix with cols
+// columns using the Hungarian algorithm. It returns the pairs whose cost
+// does not exceed maxCost, along with the unmatched rows and columns.
+func assign(cost [][]float64, cols int, maxCost float64) (matches [][2]int, unmatchedRows, unmatchedCols []int) {
+	rows := len(cost)
+
+	rowMatch := make([]int, rows)
+	colMatch := make([]int, cols)
+	for i := range rowMatch {
+		rowMatch[i] = -1
+	}
+	for j := range colMatch {
+		colMatch[j] = -1
+	}
+
+	if rows > 0 && cols > 0 {
+		transposed := rows > cols
+		at := func(i, j int) float64 { return cost[i][j] }
+		n, m := rows, cols
+		if transposed {
+			at = func(i, j int) float64 { return cost[j][i] }
+			n, m = cols, rows
+		}
+
+		for i, j := range hungarian(n, m, at) {
+			if j < 0 {
+				continue
+			}
+			row, col := i, j
+			if transposed {
+				row, col = j, i
+			}
+			if cost[row][col] <= maxCost {
+				rowMatch[row] = col
+				colMatch[col] = row
+			}
+		}
+	}
+
+	for i, j := range rowMatch {
+		if j >= 0 {
+			matches = append(matches, [2]int{i, j})
+		} else {
+			unmatchedRows = append(unmatchedRows, i)
+		}
+	}
+	for j, i := range colMatch {
+		if i < 0 {
+			unmatchedCols = append(unmatchedCols, j)
+		}
+	}
+	return matches, unmatchedRows, unmatchedCols
+}
+
+// hungarian returns the column assigned to each of n rows of an n x m cost
+// matrix with n <= m, minimising the total cost. It is the O(n^2 m)
+// shortest augmenting path formulation with row and column potentials.
+func hungarian(n, m int, cost func(i, j int) float64) []int {
+	inf := math.Inf(1)
+	u := make([]float64, n+1)
+	v := make([]float64, m+1)
+	// p[j] is the row (1-based) assigned to column j; column 0 is a
+	// virtual column holding the row being inserted.
+	p := make([]int, m+1)
+	way := make([]int, m+1)
+	minv := make([]float64, m+1)
+	used := make([]bool, m+1)
+
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+		for j := range minv {
+			minv[j] = inf
+			used[j] = false
+		}
+
+		for p[j0] != 0 {
+			used[j0] = true
+			i0 := p[j0]
+			delta := inf
+			j1 := 0
+			for j := 1; j <= m; j++ {
+				if used[j] {
+					continue
+				}
+				current := cost(i0-1, j-1) - u[i0] - v[j]
+				if current < minv[j] {
+					minv[j] = current
+					way[j] = j0
+				}
+				if minv[j] < delta {
+					delta = minv[j]
+					j1 = j
+				}
+			}
+			for j := 0; j <= m; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+		}
+
+		for j0 != 0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+
+	assignment := make([]int, n)
+	for i := range assignment {
+		assignment[i] = -1
+	}
+	for j := 1; j <= m; j++ {
+		if p[j] != 0 {
+			assignment[p[j]-1] = j - 1
+		}
+	}
+	return assignment
+}
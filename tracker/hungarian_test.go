@@ -0,0 +1,92 @@
+package tracker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAssign(t *testing.T) {
+	tests := []struct {
+		name            string
+		cost            [][]float64
+		cols            int
+		maxCost         float64
+		expectedMatches [][2]int
+		expectedRows    []int
+		expectedCols    []int
+	}{
+		{
+			name: "Square",
+			cost: [][]float64{
+				{4, 1, 3},
+				{2, 0, 5},
+				{3, 2, 2},
+			},
+			cols:            3,
+			maxCost:         10,
+			expectedMatches: [][2]int{{0, 1}, {1, 0}, {2, 2}},
+		},
+		{
+			name: "More columns",
+			cost: [][]float64{
+				{0.9, 0.1, 0.8},
+				{0.2, 0.3, 0.9},
+			},
+			cols:            3,
+			maxCost:         1,
+			expectedMatches: [][2]int{{0, 1}, {1, 0}},
+			expectedCols:    []int{2},
+		},
+		{
+			name: "More rows",
+			cost: [][]float64{
+				{0.9, 0.1},
+				{0.2, 0.3},
+				{0.05, 0.9},
+			},
+			cols:            2,
+			maxCost:         1,
+			expectedMatches: [][2]int{{0, 1}, {2, 0}},
+			expectedRows:    []int{1},
+		},
+		{
+			name: "Above max cost",
+			cost: [][]float64{
+				{0.1, 0.9},
+				{0.9, 0.95},
+			},
+			cols:            2,
+			maxCost:         0.5,
+			expectedMatches: [][2]int{{0, 0}},
+			expectedRows:    []int{1},
+			expectedCols:    []int{1},
+		},
+		{
+			name:         "No rows",
+			cols:         2,
+			maxCost:      1,
+			expectedCols: []int{0, 1},
+		},
+		{
+			name:         "No columns",
+			cost:         [][]float64{{}, {}},
+			maxCost:      1,
+			expectedRows: []int{0, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, rows, cols := assign(tt.cost, tt.cols, tt.maxCost)
+			if !reflect.DeepEqual(matches, tt.expectedMatches) {
+				t.Errorf("expected matches %v, got %v", tt.expectedMatches, matches)
+			}
+			if !reflect.DeepEqual(rows, tt.expectedRows) {
+				t.Errorf("expected unmatched rows %v, got %v", tt.expectedRows, rows)
+			}
+			if !reflect.DeepEqual(cols, tt.expectedCols) {
+				t.Errorf("expected unmatched columns %v, got %v", tt.expectedCols, cols)
+			}
+		})
+	}
+}
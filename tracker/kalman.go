@@ -0,0 +1,176 @@
+package tracker
+
+// Process and measurement noise relative to the box height, as in SORT and
+// ByteTrack.
+const (
+	stdWeightPosition = 1.0 / 20
+	stdWeightVelocity = 1.0 / 160
+)
+
+// kalmanFilter is a constant velocity Kalman filter over the box centre,
+// aspect ratio and height: x, y, a, h and their velocities.
+type kalmanFilter struct {
+	mean [8]float64
+	cov  [8][8]float64
+}
+
+// newKalmanFilter starts a filter at measurement z = x, y, a, h with zero
+// velocity.
+func newKalmanFilter(z [4]float64) *kalmanFilter {
+	k := &kalmanFilter{}
+	copy(k.mean[:4], z[:])
+
+	h := z[3]
+	std := [8]float64{
+		2 * stdWeightPosition * h,
+		2 * stdWeightPosition * h,
+		1e-2,
+		2 * stdWeightPosition * h,
+		10 * stdWeightVelocity * h,
+		10 * stdWeightVelocity * h,
+		1e-5,
+		10 * stdWeightVelocity * h,
+	}
+	for i, s := range std {
+		k.cov[i][i] = s * s
+	}
+	return k
+}
+
+// predict advances the state by one frame.
+func (k *kalmanFilter) predict() {
+	h := k.mean[3]
+	std := [8]float64{
+		stdWeightPosition * h,
+		stdWeightPosition * h,
+		1e-2,
+		stdWeightPosition * h,
+		stdWeightVelocity * h,
+		stdWeightVelocity * h,
+		1e-5,
+		stdWeightVelocity * h,
+	}
+
+	for i := 0; i < 4; i++ {
+		k.mean[i] += k.mean[i+4]
+	}
+
+	// cov = F cov F^T + Q, where F adds each velocity to its position.
+	var fc [8][8]float64
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			fc[i][j] = k.cov[i][j]
+			if i < 4 {
+				fc[i][j] += k.cov[i+4][j]
+			}
+		}
+	}
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			k.cov[i][j] = fc[i][j]
+			if j < 4 {
+				k.cov[i][j] += fc[i][j+4]
+			}
+		}
+		k.cov[i][i] += std[i] * std[i]
+	}
+}
+
+// update corrects the state with measurement z = x, y, a, h.
+func (k *kalmanFilter) update(z [4]float64) {
+	h := k.mean[3]
+	std := [4]float64{stdWeightPosition * h, stdWeightPosition * h, 1e-1, stdWeightPosition * h}
+
+	// The measurement picks the first four state components, so the
+	// projected covariance is the top-left block of cov.
+	var s [4][4]float64
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			s[i][j] = k.cov[i][j]
+		}
+		s[i][i] += std[i] * std[i]
+	}
+	sInv, ok := invert4(s)
+	if !ok {
+		return
+	}
+
+	// gain = cov H^T S^-1
+	var gain [8][4]float64
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 4; j++ {
+			for l := 0; l < 4; l++ {
+				gain[i][j] += k.cov[i][l] * sInv[l][j]
+			}
+		}
+	}
+
+	var innovation [4]float64
+	for i := 0; i < 4; i++ {
+		innovation[i] = z[i] - k.mean[i]
+	}
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 4; j++ {
+			k.mean[i] += gain[i][j] * innovation[j]
+		}
+	}
+
+	// cov -= gain S gain^T, which equals gain H cov.
+	var correction [8][8]float64
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			for l := 0; l < 4; l++ {
+				correction[i][j] += gain[i][l] * k.cov[l][j]
+			}
+		}
+	}
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			k.cov[i][j] -= correction[i][j]
+		}
+	}
+}
+
+// invert4 inverts a 4x4 matrix by Gauss-Jordan elimination with partial
+// pivoting. It reports false for a singular matrix.
+func invert4(m [4][4]float64) ([4][4]float64, bool) {
+	var inv [4][4]float64
+	for i := range inv {
+		inv[i][i] = 1
+	}
+
+	for col := 0; col < 4; col++ {
+		pivot := col
+		for row := col + 1; row < 4; row++ {
+			if abs(m[row][col]) > abs(m[pivot][col]) {
+				pivot = row
+			}
+		}
+		if m[pivot][col] == 0 {
+			return inv, false
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		inv[col], inv[pivot] = inv[pivot], inv[col]
+
+		scale := 1 / m[col][col]
+		for j := 0; j < 4; j++ {
+			m[col][j] *= scale
+			inv[col][j] *= scale
+		}
+		for row := 0; row < 4; row++ {
+			if row == col {
+				continue
+			}
+			factor := m[row][col]
+			for j := 0; j < 4; j++ {
+				m[row][j] -= factor * m[col][j]
+				inv[row][j] -= factor * inv[col][j]
+			}
+		}
+	}
+	return inv, true
+}
+
+func abs(x float64) float64 {
+	return max(x, -x)
+}
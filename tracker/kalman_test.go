@@ -0,0 +1,56 @@
+package tracker
+
+import (
+	"math"
+	"testing"
+)
+
+func TestKalmanFilterConstantVelocity(t *testing.T) {
+	k := newKalmanFilter([4]float64{100, 50, 0.5, 40})
+	for frame := 1; frame <= 30; frame++ {
+		k.predict()
+		k.update([4]float64{100 + 3*float64(frame), 50 - 2*float64(frame), 0.5, 40})
+	}
+
+	if math.Abs(k.mean[4]-3) > 0.1 || math.Abs(k.mean[5]+2) > 0.1 {
+		t.Errorf("expected velocity (3, -2), got (%f, %f)", k.mean[4], k.mean[5])
+	}
+
+	k.predict()
+	if math.Abs(k.mean[0]-193) > 0.5 || math.Abs(k.mean[1]+12) > 0.5 {
+		t.Errorf("expected prediction (193, -12), got (%f, %f)", k.mean[0], k.mean[1])
+	}
+}
+
+func TestInvert4(t *testing.T) {
+	m := [4][4]float64{
+		{4, 1, 0, 0},
+		{1, 3, 0, 1},
+		{0, 0, 2, 0},
+		{0, 1, 0, 5},
+	}
+	inv, ok := invert4(m)
+	if !ok {
+		t.Fatalf("expected matrix to be invertible")
+	}
+
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			var product float64
+			for l := 0; l < 4; l++ {
+				product += m[i][l] * inv[l][j]
+			}
+			expected := 0.0
+			if i == j {
+				expected = 1
+			}
+			if math.Abs(product-expected) > 1e-9 {
+				t.Errorf("expected identity at (%d, %d), got %f", i, j, product)
+			}
+		}
+	}
+
+	if _, ok := invert4([4][4]float64{}); ok {
+		t.Errorf("expected singular matrix to fail")
+	}
+}
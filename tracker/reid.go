@@ -0,0 +1,151 @@
+package tracker
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/disintegration/imaging"
+	engine "github.com/zazamaza/yolo-object-detection-go/internal/engine"
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// IEmbedder computes an appearance feature for every box of a frame.
+// Features are L2 normalized; boxes without pixels get a nil feature.
+type IEmbedder interface {
+	Embed(frame image.Image, boxes []utils.BoundingBox) ([][]float32, error)
+}
+
+// ReIDConfiguration describes a person or vehicle re-identification model
+// with a [batch, 3, height, width] input and a [batch, features] output,
+// such as OSNet or FastReID exports.
+type ReIDConfiguration struct {
+	ModelPath  string
+	InputName  string
+	OutputName string
+	InputShape []int64
+	// FeatureSize is the length of the embedding.
+	FeatureSize int
+	// Mean and Std normalize RGB values scaled to 0-1.
+	Mean [3]float32
+	Std  [3]float32
+}
+
+// NewReIDConfiguration returns the common 256x128 input with ImageNet
+// normalization.
+func NewReIDConfiguration() ReIDConfiguration {
+	return ReIDConfiguration{
+		ModelPath:   "reid.onnx",
+		InputName:   "images",
+		OutputName:  "output",
+		InputShape:  []int64{1, 3, 256, 128},
+		FeatureSize: 512,
+		Mean:        [3]float32{0.485, 0.456, 0.406},
+		Std:         [3]float32{0.229, 0.224, 0.225},
+	}
+}
+
+// ReIDEmbedder runs a ReID model on detection crops. It is not safe for
+// concurrent use.
+type ReIDEmbedder struct {
+	engine        engine.IEngine
+	configuration ReIDConfiguration
+	input         []float32
+}
+
+func NewReIDEmbedder(configuration *ReIDConfiguration) (*ReIDEmbedder, error) {
+	batch := configuration.InputShape[0]
+	engine, err := engine.NewEngine(
+		configuration.ModelPath,
+		configuration.InputName,
+		configuration.InputShape,
+		[]engine.OutputSpec{
+			{Name: configuration.OutputName, Shape: []int64{batch, int64(configuration.FeatureSize)}, Type: engine.Float32},
+		},
+		engine.CPU,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	size := 1
+	for _, dim := range configuration.InputShape {
+		size *= int(dim)
+	}
+	return &ReIDEmbedder{
+		engine:        engine,
+		configuration: *configuration,
+		input:         make([]float32, size),
+	}, nil
+}
+
+// Embed crops every box from frame and runs the crops through the model
+// in batches.
+func (r *ReIDEmbedder) Embed(frame image.Image, boxes []utils.BoundingBox) ([][]float32, error) {
+	shape := r.configuration.InputShape
+	batchSize := max(int(shape[0]), 1)
+	height, width := int(shape[2]), int(shape[3])
+	imageSize := 3 * height * width
+	featureSize := r.configuration.FeatureSize
+
+	var crops []image.Image
+	var indices []int
+	bounds := frame.Bounds()
+	for i := range boxes {
+		rect := image.Rect(
+			int(math.Floor(float64(boxes[i].X1))), int(math.Floor(float64(boxes[i].Y1))),
+			int(math.Ceil(float64(boxes[i].X2))), int(math.Ceil(float64(boxes[i].Y2))),
+		).Add(bounds.Min).Intersect(bounds)
+		if rect.Empty() {
+			continue
+		}
+		crops = append(crops, utils.SubImage(frame, rect))
+		indices = append(indices, i)
+	}
+
+	features := make([][]float32, len(boxes))
+	for start := 0; start < len(crops); start += batchSize {
+		batch := crops[start:min(start+batchSize, len(crops))]
+		for b, crop := range batch {
+			r.preProcess(crop, r.input[b*imageSize:(b+1)*imageSize], width, height)
+		}
+
+		r.engine.SetInput(&r.input)
+		err := r.engine.Run()
+		if err != nil {
+			return nil, fmt.Errorf("error running ORT session: %s", err)
+		}
+
+		output := r.engine.GetOutput()
+		for b := range batch {
+			feature := make([]float32, featureSize)
+			copy(feature, output[b*featureSize:(b+1)*featureSize])
+			normalize(feature)
+			features[indices[start+b]] = feature
+		}
+	}
+	return features, nil
+}
+
+// preProcess resizes crop to the model input and writes normalized CHW
+// values into dst.
+func (r *ReIDEmbedder) preProcess(crop image.Image, dst []float32, width, height int) {
+	resized := imaging.Resize(crop, width, height, imaging.Linear)
+	channelSize := width * height
+	const div255 = 1.0 / 255.0
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*width + x
+			offset := y*resized.Stride + x*4
+			for c := 0; c < 3; c++ {
+				value := float32(resized.Pix[offset+c]) * div255
+				dst[c*channelSize+i] = (value - r.configuration.Mean[c]) / r.configuration.Std[c]
+			}
+		}
+	}
+}
+
+func (r *ReIDEmbedder) Destroy() {
+	r.engine.Destroy()
+}
@@ -0,0 +1,77 @@
+package tracker
+
+import (
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// SORT is the Simple Online and Realtime Tracker: a Kalman filter per
+// track and Hungarian matching of predicted boxes to detections by IoU.
+// It is not safe for concurrent use.
+type SORT struct {
+	// MaxAge is the number of frames a confirmed track survives without a
+	// match.
+	MaxAge int
+	// MinHits is the number of matches that confirm a track.
+	MinHits int
+	// IoUThreshold is the minimum overlap of a match.
+	IoUThreshold float32
+	// ClassAware only matches detections of the track's class.
+	ClassAware bool
+
+	tracks []*Track
+	nextID int
+	frame  int
+}
+
+func NewSORT() *SORT {
+	return &SORT{
+		MaxAge:       1,
+		MinHits:      3,
+		IoUThreshold: 0.3,
+	}
+}
+
+// Update consumes the detections of the next frame and returns the live
+// tracks: confirmed tracks matched in this frame, lost tracks within
+// MaxAge and tentative tracks.
+func (s *SORT) Update(boxes []utils.BoundingBox) []Track {
+	s.frame++
+	for _, track := range s.tracks {
+		track.predict()
+	}
+
+	cost := iouCost(s.tracks, boxes, s.ClassAware, false)
+	matches, unmatchedTracks, unmatchedBoxes := assign(cost, len(boxes), float64(1-s.IoUThreshold))
+
+	for _, match := range matches {
+		track := s.tracks[match[0]]
+		track.update(boxes[match[1]])
+		// Tracks are confirmed straight away during the first frames, so
+		// objects present from the start are reported immediately.
+		if track.Hits >= s.MinHits || s.frame <= s.MinHits {
+			track.State = Confirmed
+		}
+	}
+
+	for _, i := range unmatchedTracks {
+		track := s.tracks[i]
+		switch {
+		case track.State == Tentative || track.TimeSinceUpdate > s.MaxAge:
+			track.State = Removed
+		default:
+			track.State = Lost
+		}
+	}
+
+	for _, j := range unmatchedBoxes {
+		s.nextID++
+		track := newTrack(s.nextID, boxes[j])
+		if s.frame <= s.MinHits || s.MinHits <= 1 {
+			track.State = Confirmed
+		}
+		s.tracks = append(s.tracks, track)
+	}
+
+	s.tracks = prune(s.tracks)
+	return snapshot(s.tracks)
+}
@@ -0,0 +1,217 @@
+package tracker
+
+import (
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+type TrackState int
+
+const (
+	// Tentative tracks have not been matched for enough frames yet.
+	Tentative TrackState = iota
+	// Confirmed tracks were matched in the current frame.
+	Confirmed
+	// Lost tracks were confirmed but missed in recent frames; their box is
+	// the motion model's prediction.
+	Lost
+	// Removed tracks are dropped and never returned again.
+	Removed
+)
+
+func (s TrackState) String() string {
+	switch s {
+	case Tentative:
+		return "tentative"
+	case Confirmed:
+		return "confirmed"
+	case Lost:
+		return "lost"
+	default:
+		return "removed"
+	}
+}
+
+// Track is an object followed across frames. Box carries the label and
+// score of the last matched detection and the filtered position.
+type Track struct {
+	ID    int
+	Box   utils.BoundingBox
+	State TrackState
+	// Age is the number of frames since the track started.
+	Age int
+	// Hits is the number of frames the track was matched in.
+	Hits int
+	// TimeSinceUpdate is the number of frames since the last match.
+	TimeSinceUpdate int
+	// VelocityX and VelocityY are the motion of the box centre in pixels
+	// per frame.
+	VelocityX, VelocityY float32
+
+	kalman     *kalmanFilter
+	appearance *appearance
+}
+
+// ITracker associates the detections of consecutive frames.
+type ITracker interface {
+	// Update consumes the detections of the next frame and returns the
+	// live tracks.
+	Update(boxes []utils.BoundingBox) []Track
+}
+
+// forbidden is the association cost of pairs that must never match. It is
+// finite so the assignment potentials stay well defined.
+const forbidden = 1e6
+
+func newTrack(id int, box utils.BoundingBox) *Track {
+	t := &Track{
+		ID:     id,
+		Box:    box,
+		State:  Tentative,
+		Age:    1,
+		Hits:   1,
+		kalman: newKalmanFilter(measurement(&box)),
+	}
+	t.sync()
+	return t
+}
+
+// predict moves the track to its expected position in the next frame.
+func (t *Track) predict() {
+	if t.State == Lost {
+		// Freeze height velocity of lost tracks so their box does not
+		// collapse, as ByteTrack does.
+		t.kalman.mean[7] = 0
+	}
+	t.kalman.predict()
+	t.Age++
+	t.TimeSinceUpdate++
+	t.sync()
+}
+
+// update corrects the track with a matched detection.
+func (t *Track) update(box utils.BoundingBox) {
+	t.kalman.update(measurement(&box))
+	t.Box.Label = box.Label
+	t.Box.ClassID = box.ClassID
+	t.Box.Confidence = box.Confidence
+	t.Hits++
+	t.TimeSinceUpdate = 0
+	t.sync()
+}
+
+// compensate moves the track with the camera motion between the previous
+// and the current frame.
+func (t *Track) compensate(motion Affine) {
+	mean := &t.kalman.mean
+	scale := motion.scale()
+
+	var transform [8][8]float64
+	for _, offset := range []int{0, 4} {
+		transform[offset][offset] = motion[0][0]
+		transform[offset][offset+1] = motion[0][1]
+		transform[offset+1][offset] = motion[1][0]
+		transform[offset+1][offset+1] = motion[1][1]
+		transform[offset+2][offset+2] = 1
+		transform[offset+3][offset+3] = scale
+	}
+
+	var transformed [8]float64
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			transformed[i] += transform[i][j] * mean[j]
+		}
+	}
+	transformed[0] += motion[0][2]
+	transformed[1] += motion[1][2]
+	*mean = transformed
+
+	// cov = T cov T^T
+	var tc [8][8]float64
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			for l := 0; l < 8; l++ {
+				tc[i][j] += transform[i][l] * t.kalman.cov[l][j]
+			}
+		}
+	}
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			var sum float64
+			for l := 0; l < 8; l++ {
+				sum += tc[i][l] * transform[j][l]
+			}
+			t.kalman.cov[i][j] = sum
+		}
+	}
+	t.sync()
+}
+
+// sync refreshes the box and velocity from the Kalman state.
+func (t *Track) sync() {
+	mean := &t.kalman.mean
+	width := mean[2] * mean[3]
+	t.Box.X1 = float32(mean[0] - width/2)
+	t.Box.Y1 = float32(mean[1] - mean[3]/2)
+	t.Box.X2 = float32(mean[0] + width/2)
+	t.Box.Y2 = float32(mean[1] + mean[3]/2)
+	t.VelocityX = float32(mean[4])
+	t.VelocityY = float32(mean[5])
+}
+
+// measurement converts a box to the centre, aspect ratio and height used
+// by the Kalman filter.
+func measurement(box *utils.BoundingBox) [4]float64 {
+	width := float64(box.X2 - box.X1)
+	height := max(float64(box.Y2-box.Y1), 1e-6)
+	return [4]float64{
+		float64(box.X1) + width/2,
+		float64(box.Y1) + height/2,
+		width / height,
+		height,
+	}
+}
+
+// iouCost returns 1 - IoU between every track and box. With classAware,
+// pairs of different classes are forbidden. With fuseScore the IoU is
+// weighted by the detection score.
+func iouCost(tracks []*Track, boxes []utils.BoundingBox, classAware, fuseScore bool) [][]float64 {
+	cost := make([][]float64, len(tracks))
+	for i, track := range tracks {
+		cost[i] = make([]float64, len(boxes))
+		for j := range boxes {
+			if classAware && track.Box.ClassID != boxes[j].ClassID {
+				cost[i][j] = forbidden
+				continue
+			}
+			similarity := track.Box.IoU(&boxes[j])
+			if fuseScore {
+				similarity *= boxes[j].Confidence
+			}
+			cost[i][j] = float64(1 - similarity)
+		}
+	}
+	return cost
+}
+
+// snapshot copies the tracks that are not removed.
+func snapshot(tracks []*Track) []Track {
+	results := make([]Track, 0, len(tracks))
+	for _, track := range tracks {
+		if track.State != Removed {
+			results = append(results, *track)
+		}
+	}
+	return results
+}
+
+// prune drops removed tracks, reusing the backing array.
+func prune(tracks []*Track) []*Track {
+	results := tracks[:0]
+	for _, track := range tracks {
+		if track.State != Removed {
+			results = append(results, track)
+		}
+	}
+	clear(tracks[len(results):])
+	return results
+}
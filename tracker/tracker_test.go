@@ -0,0 +1,124 @@
+package tracker
+
+import (
+	"testing"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+func box(x, y float32, confidence float32) utils.BoundingBox {
+	return utils.BoundingBox{Label: "person", Confidence: confidence, X1: x, Y1: y, X2: x + 20, Y2: y + 40}
+}
+
+func trackByID(tracks []Track, id int) *Track {
+	for i := range tracks {
+		if tracks[i].ID == id {
+			return &tracks[i]
+		}
+	}
+	return nil
+}
+
+func TestSORT(t *testing.T) {
+	sort := NewSORT()
+	sort.MaxAge = 2
+
+	var tracks []Track
+	for frame := 0; frame < 5; frame++ {
+		offset := float32(frame * 4)
+		tracks = sort.Update([]utils.BoundingBox{
+			box(10+offset, 10, 0.9),
+			box(200-offset, 100, 0.8),
+		})
+	}
+
+	if len(tracks) != 2 {
+		t.Fatalf("expected 2 tracks, got %d", len(tracks))
+	}
+	first, second := trackByID(tracks, 1), trackByID(tracks, 2)
+	if first == nil || second == nil {
+		t.Fatalf("expected stable IDs 1 and 2, got %+v", tracks)
+	}
+	if first.State != Confirmed || first.Age != 5 || first.Hits != 5 {
+		t.Errorf("expected confirmed track with age and hits 5, got %+v", first)
+	}
+	if first.VelocityX < 3 || second.VelocityX > -3 {
+		t.Errorf("expected opposite horizontal velocities, got %f and %f", first.VelocityX, second.VelocityX)
+	}
+
+	// A new object starts tentative after the first frames.
+	tracks = sort.Update([]utils.BoundingBox{box(30, 10, 0.9), box(500, 500, 0.9)})
+	if track := trackByID(tracks, 3); track == nil || track.State != Tentative {
+		t.Errorf("expected tentative track 3, got %+v", track)
+	}
+	if track := trackByID(tracks, 2); track == nil || track.State != Lost {
+		t.Errorf("expected track 2 to be lost, got %+v", track)
+	}
+
+	// Tentative tracks are dropped on their first miss, lost tracks after
+	// MaxAge frames.
+	sort.Update([]utils.BoundingBox{box(34, 10, 0.9)})
+	tracks = sort.Update([]utils.BoundingBox{box(38, 10, 0.9)})
+	if len(tracks) != 1 || tracks[0].ID != 1 {
+		t.Errorf("expected only track 1 to remain, got %+v", tracks)
+	}
+}
+
+func TestByteTrackLowScoreAssociation(t *testing.T) {
+	bt := NewByteTrack()
+
+	tracks := bt.Update([]utils.BoundingBox{box(10, 10, 0.9)})
+	if len(tracks) != 1 || tracks[0].State != Confirmed {
+		t.Fatalf("expected a confirmed track on the first frame, got %+v", tracks)
+	}
+
+	// An occluded frame with a low score box keeps the track alive.
+	tracks = bt.Update([]utils.BoundingBox{box(12, 10, 0.3)})
+	if len(tracks) != 1 || tracks[0].State != Confirmed || tracks[0].TimeSinceUpdate != 0 {
+		t.Errorf("expected the low score box to match, got %+v", tracks)
+	}
+
+	// Low score boxes never start tracks.
+	tracks = bt.Update([]utils.BoundingBox{box(14, 10, 0.9), box(300, 300, 0.3)})
+	if len(tracks) != 1 || tracks[0].ID != 1 {
+		t.Errorf("expected only track 1, got %+v", tracks)
+	}
+}
+
+func TestByteTrackLostAndRecovered(t *testing.T) {
+	bt := NewByteTrack()
+	bt.MaxLost = 3
+
+	bt.Update([]utils.BoundingBox{box(10, 10, 0.9)})
+	tracks := bt.Update(nil)
+	if len(tracks) != 1 || tracks[0].State != Lost {
+		t.Fatalf("expected a lost track, got %+v", tracks)
+	}
+
+	tracks = bt.Update([]utils.BoundingBox{box(10, 10, 0.9)})
+	if len(tracks) != 1 || tracks[0].ID != 1 || tracks[0].State != Confirmed {
+		t.Errorf("expected track 1 to be recovered, got %+v", tracks)
+	}
+
+	for frame := 0; frame < 4; frame++ {
+		tracks = bt.Update(nil)
+	}
+	if len(tracks) != 0 {
+		t.Errorf("expected the track to be removed after MaxLost frames, got %+v", tracks)
+	}
+}
+
+func TestByteTrackTentative(t *testing.T) {
+	bt := NewByteTrack()
+	bt.Update([]utils.BoundingBox{box(10, 10, 0.9)})
+
+	tracks := bt.Update([]utils.BoundingBox{box(10, 10, 0.9), box(200, 200, 0.9)})
+	if track := trackByID(tracks, 2); track == nil || track.State != Tentative {
+		t.Fatalf("expected tentative track 2, got %+v", tracks)
+	}
+
+	tracks = bt.Update([]utils.BoundingBox{box(10, 10, 0.9), box(201, 200, 0.9)})
+	if track := trackByID(tracks, 2); track == nil || track.State != Confirmed {
+		t.Errorf("expected track 2 to be confirmed, got %+v", tracks)
+	}
+}
@@ -0,0 +1,142 @@
+package yolo
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/disintegration/imaging"
+	models "github.com/zazamaza/yolo-object-detection-go/internal/models"
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+// Augmentation is one test-time view of the input image.
+type Augmentation struct {
+	// Scale is the size of the image content relative to a plain
+	// prediction. Below 1 the image is padded so objects appear smaller;
+	// above 1 the centre of the image is zoomed in.
+	Scale          float64
+	FlipHorizontal bool
+}
+
+// TTAFusion is how the detections of the augmented views are fused. It is
+// the Fusion shared with ensembles.
+type TTAFusion = Fusion
+
+const (
+	TTAFusionWBF = FusionWBF
+	TTAFusionNMS = FusionNMS
+)
+
+// TTAOptions configures test-time augmentation.
+type TTAOptions struct {
+	Augmentations []Augmentation
+	Fusion        TTAFusion
+	// IoUThreshold is the overlap at which boxes from different views are
+	// fused.
+	IoUThreshold float32
+}
+
+// DefaultTTAOptions mirrors the Ultralytics augmentations: the original,
+// a flipped view at 0.83 scale and a view at 0.67 scale.
+func DefaultTTAOptions() TTAOptions {
+	return TTAOptions{
+		Augmentations: []Augmentation{
+			{Scale: 1},
+			{Scale: 0.83, FlipHorizontal: true},
+			{Scale: 0.67},
+		},
+		Fusion:       TTAFusionWBF,
+		IoUThreshold: 0.55,
+	}
+}
+
+func (t *TTAOptions) fuser() utils.ISuppressor {
+	return t.Fusion.suppressor(len(t.Augmentations))
+}
+
+// PredictTTA runs the model on every augmented view of img in batches,
+// maps the detections back to img and fuses them.
+func (yo *YOLO) PredictTTA(img image.Image, tta TTAOptions, opts ...PredictOption) ([]utils.BoundingBox, error) {
+	options := yo.defaults
+	options.apply(opts)
+
+	img, offset, ok := cropROI(img, options.ROI)
+	if !ok {
+		return []utils.BoundingBox{}, nil
+	}
+
+	views := make([]image.Image, len(tta.Augmentations))
+	placements := make([]func(*utils.BoundingBox), len(tta.Augmentations))
+	for i, augmentation := range tta.Augmentations {
+		view, restore := augment(img, augmentation)
+		views[i] = view
+		placements[i] = func(box *utils.BoundingBox) {
+			restore(box)
+			box.Translate(float32(offset.X), float32(offset.Y))
+		}
+	}
+
+	postProcessOptions := options.postProcessOptions()
+	batches, err := yo.predictBatch(views, postProcessOptions, placements)
+	if err != nil {
+		return nil, err
+	}
+
+	boxes := []utils.BoundingBox{}
+	for i, viewBoxes := range batches {
+		for _, box := range viewBoxes {
+			placements[i](&box)
+			if box.Area() > 0 {
+				boxes = append(boxes, box)
+			}
+		}
+	}
+
+	if !options.RawCandidates {
+		boxes = utils.SuppressBatched(tta.fuser(), boxes, tta.IoUThreshold, options.AgnosticNMS)
+		// Fused boxes can move into a mask although none of their parts was.
+		boxes = utils.FilterExcluded(boxes, options.ExclusionMasks)
+		boxes = models.LimitDetections(boxes, postProcessOptions)
+	}
+	return boxes, nil
+}
+
+// augment returns the augmented view of img and a function mapping a box
+// detected in the view back to img coordinates.
+func augment(img image.Image, augmentation Augmentation) (image.Image, func(*utils.BoundingBox)) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	view := img
+	var origin image.Point
+
+	switch scale := augmentation.Scale; {
+	case scale > 0 && scale < 1:
+		canvasWidth := int(math.Round(float64(width) / scale))
+		canvasHeight := int(math.Round(float64(height) / scale))
+		canvas := imaging.New(canvasWidth, canvasHeight, color.NRGBA{114, 114, 114, 255})
+		view = imaging.Paste(canvas, img, image.Point{})
+	case scale > 1:
+		cropWidth := int(math.Round(float64(width) / scale))
+		cropHeight := int(math.Round(float64(height) / scale))
+		origin = image.Pt((width-cropWidth)/2, (height-cropHeight)/2)
+		view = utils.SubImage(img, image.Rectangle{Min: origin, Max: origin.Add(image.Pt(cropWidth, cropHeight))}.Add(bounds.Min))
+	}
+
+	viewWidth := float32(view.Bounds().Dx())
+	if augmentation.FlipHorizontal {
+		view = imaging.FlipH(view)
+	}
+
+	restore := func(box *utils.BoundingBox) {
+		if augmentation.FlipHorizontal {
+			box.X1, box.X2 = viewWidth-box.X2, viewWidth-box.X1
+		}
+		box.Translate(float32(origin.X), float32(origin.Y))
+		box.X1 = min(max(box.X1, 0), float32(width))
+		box.Y1 = min(max(box.Y1, 0), float32(height))
+		box.X2 = min(max(box.X2, 0), float32(width))
+		box.Y2 = min(max(box.Y2, 0), float32(height))
+	}
+	return view, restore
+}
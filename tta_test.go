@@ -0,0 +1,131 @@
+package yolo
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+
+	"github.com/zazamaza/yolo-object-detection-go/internal/utils"
+)
+
+func TestAugment(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	for y := 15; y < 25; y++ {
+		for x := 30; x < 40; x++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+
+	tests := []struct {
+		name         string
+		augmentation Augmentation
+		viewSize     image.Point
+		// redAt is where the red square starts in the view.
+		redAt    image.Point
+		detected utils.BoundingBox
+		expected utils.BoundingBox
+	}{
+		{
+			name:         "Original",
+			augmentation: Augmentation{Scale: 1},
+			viewSize:     image.Pt(100, 50),
+			redAt:        image.Pt(30, 15),
+			detected:     utils.BoundingBox{X1: 30, Y1: 15, X2: 40, Y2: 25},
+			expected:     utils.BoundingBox{X1: 30, Y1: 15, X2: 40, Y2: 25},
+		},
+		{
+			name:         "Flipped",
+			augmentation: Augmentation{Scale: 1, FlipHorizontal: true},
+			viewSize:     image.Pt(100, 50),
+			redAt:        image.Pt(60, 15),
+			detected:     utils.BoundingBox{X1: 60, Y1: 15, X2: 70, Y2: 25},
+			expected:     utils.BoundingBox{X1: 30, Y1: 15, X2: 40, Y2: 25},
+		},
+		{
+			name:         "Shrunk into padding",
+			augmentation: Augmentation{Scale: 0.5},
+			viewSize:     image.Pt(200, 100),
+			redAt:        image.Pt(30, 15),
+			detected:     utils.BoundingBox{X1: 90, Y1: 10, X2: 150, Y2: 20},
+			expected:     utils.BoundingBox{X1: 90, Y1: 10, X2: 100, Y2: 20},
+		},
+		{
+			name:         "Zoomed and flipped",
+			augmentation: Augmentation{Scale: 2, FlipHorizontal: true},
+			viewSize:     image.Pt(50, 25),
+			redAt:        image.Pt(35, 3),
+			detected:     utils.BoundingBox{X1: 35, Y1: 3, X2: 45, Y2: 13},
+			expected:     utils.BoundingBox{X1: 30, Y1: 15, X2: 40, Y2: 25},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view, restore := augment(img, tt.augmentation)
+
+			bounds := view.Bounds()
+			if bounds.Size() != tt.viewSize {
+				t.Fatalf("expected view size %v, got %v", tt.viewSize, bounds.Size())
+			}
+			if r, _, _, _ := view.At(bounds.Min.X+tt.redAt.X, bounds.Min.Y+tt.redAt.Y).RGBA(); r != 0xffff {
+				t.Errorf("expected red pixel at %v", tt.redAt)
+			}
+
+			box := tt.detected
+			restore(&box)
+			if box != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, box)
+			}
+		})
+	}
+}
+
+func TestTTAOptionsFuser(t *testing.T) {
+	options := DefaultTTAOptions()
+	if wbf, ok := options.fuser().(*utils.WeightedBoxesFusion); !ok || wbf.Models != 3 {
+		t.Errorf("expected WBF over 3 views, got %+v", options.fuser())
+	}
+
+	options.Fusion = TTAFusionNMS
+	if _, ok := options.fuser().(*utils.GreedyNMS); !ok {
+		t.Errorf("expected NMS, got %+v", options.fuser())
+	}
+}
+
+func TestPredictTTA(t *testing.T) {
+	objects := []utils.BoundingBox{
+		{Label: "person", Confidence: 0.9, X1: 50, Y1: 30, X2: 80, Y2: 60},
+		{Label: "car", ClassID: 1, Confidence: 0.8, X1: 120, Y1: 50, X2: 150, Y2: 90},
+	}
+	colors := []color.NRGBA{{R: 255, A: 255}, {B: 255, A: 255}}
+	img := image.NewNRGBA(image.Rect(0, 0, 200, 120))
+	paint(img, objects, colors)
+
+	preProcess := &fakePreProcess{}
+	model := fakeModel(preProcess, &colorPostProcess{preProcess: preProcess, objects: objects, colors: colors}, 2)
+
+	// The views cover padding, flipping and cropping; the crop keeps both
+	// objects whole.
+	tta := TTAOptions{
+		Augmentations: []Augmentation{{Scale: 1}, {Scale: 0.8, FlipHorizontal: true}, {Scale: 1.25}},
+		Fusion:        TTAFusionNMS,
+		IoUThreshold:  0.55,
+	}
+	boxes, err := model.PredictTTA(img, tta, WithROI(image.Rect(20, 10, 180, 110)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sizes := []image.Point{}
+	for _, view := range preProcess.images {
+		sizes = append(sizes, view.Bounds().Size())
+	}
+	expectedSizes := []image.Point{{160, 100}, {200, 125}, {128, 80}}
+	if !reflect.DeepEqual(sizes, expectedSizes) {
+		t.Errorf("expected views of %v, got %v", expectedSizes, sizes)
+	}
+	if !reflect.DeepEqual(boxes, objects) {
+		t.Errorf("expected %+v, got %+v", objects, boxes)
+	}
+}
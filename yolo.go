@@ -3,6 +3,11 @@ package yolo
 import (
 	"fmt"
 	"image"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
 
 	engine "github.com/zazamaza/yolo-object-detection-go/internal/engine"
 	models "github.com/zazamaza/yolo-object-detection-go/internal/models"
@@ -15,51 +20,61 @@ type YOLO struct {
 	postProcessor models.IPostProcess
 	inputShape    int
 	outputShape   int
+	batchSize     int
+	letterbox     utils.LetterboxOptions
+	normalization models.Normalization
+	classes       []string
 	version       models.YOLOVersion
+	defaults      PredictOptions
+	// scratch is the batch reused by prepare.
+	scratch batchInput
+	// name is the model file name without its extension.
+	name string
+	// timings are those of the last prediction.
+	timings Timings
 }
 
-func NewYOLOv5(modelPath string) (*YOLO, error) {
+func NewYOLOv5(modelPath string, defaults ...PredictOption) (*YOLO, error) {
 	configuration := models.NewYOLOConfiguration()
 	configuration.ModelPath = modelPath
 	configuration.Version = models.YOLOv5
-	return newYOLOHelper(&configuration)
+	return newYOLOHelper(&configuration, defaults...)
 }
 
-func NewYOLOv8(modelPath string) (*YOLO, error) {
+func NewYOLOv8(modelPath string, defaults ...PredictOption) (*YOLO, error) {
 	configuration := models.NewYOLOConfiguration()
 	configuration.ModelPath = modelPath
 	configuration.Version = models.YOLOv8
-	return newYOLOHelper(&configuration)
+	return newYOLOHelper(&configuration, defaults...)
 }
 
-func NewYOLOv10(modelPath string) (*YOLO, error) {
+func NewYOLOv10(modelPath string, defaults ...PredictOption) (*YOLO, error) {
 	configuration := models.NewYOLOConfiguration()
 	configuration.ModelPath = modelPath
 	configuration.Version = models.YOLOv10
 	configuration.InputShape = []int64{1, 3, 640, 640}
 	configuration.OutputShape = []int64{1, 300, 6}
-	return newYOLOHelper(&configuration)
+	return newYOLOHelper(&configuration, defaults...)
 }
 
-func NewYOLOv11(modelPath string) (*YOLO, error) {
+func NewYOLOv11(modelPath string, defaults ...PredictOption) (*YOLO, error) {
 	configuration := models.NewYOLOConfiguration()
 	configuration.ModelPath = modelPath
 	configuration.Version = models.YOLOv11
-	return newYOLOHelper(&configuration)
+	return newYOLOHelper(&configuration, defaults...)
 }
 
-func NewYOLOWithConfiguration(configuration *models.YOLOConfiguration) (*YOLO, error) {
+func NewYOLOWithConfiguration(configuration *models.YOLOConfiguration, defaults ...PredictOption) (*YOLO, error) {
 	fmt.Println(configuration.Version)
-	return newYOLOHelper(configuration)
+	return newYOLOHelper(configuration, defaults...)
 }
 
-func newYOLOHelper(configuration *models.YOLOConfiguration) (*YOLO, error) {
+func newYOLOHelper(configuration *models.YOLOConfiguration, defaults ...PredictOption) (*YOLO, error) {
 	engine, err := engine.NewEngine(
 		configuration.ModelPath,
 		configuration.InputName,
-		configuration.OutputName,
 		configuration.InputShape,
-		configuration.OutputShape,
+		configuration.Outputs(),
 		engine.CPU,
 	)
 	if err != nil {
@@ -68,10 +83,21 @@ func newYOLOHelper(configuration *models.YOLOConfiguration) (*YOLO, error) {
 
 	imageUtils := utils.ImageUtils{}
 	inputShape := int(configuration.InputShape[2])
-	outputShape := int(configuration.OutputShape[2])
+	outputShape := int(configuration.OutputShape[len(configuration.OutputShape)-1])
 
 	var postProcessor models.IPostProcess
-	if configuration.Version == models.YOLOv10 {
+	if configuration.EndToEnd != nil {
+		maxDetections := configuration.EndToEnd.MaxDetections
+		if configuration.EndToEnd.Layout == models.NMSLayoutPacked {
+			maxDetections = int(configuration.OutputShape[1])
+		}
+		postProcessor = &models.EndToEndPostProcess{
+			Layout:        configuration.EndToEnd.Layout,
+			BoxFormat:     configuration.EndToEnd.BoxFormat,
+			MaxDetections: maxDetections,
+			Classes:       configuration.Classes,
+		}
+	} else if configuration.Version == models.YOLOv10 {
 		postProcessor = &models.YOLOv10PostProcess{
 			InputShape:  inputShape,
 			OutputShape: outputShape,
@@ -82,51 +108,213 @@ func newYOLOHelper(configuration *models.YOLOConfiguration) (*YOLO, error) {
 			OutputShape: outputShape,
 			Classes:     configuration.Classes,
 			ImageUtils:  &imageUtils,
+			Suppressor:  configuration.Suppressor,
 		}
 	}
 
+	predictDefaults := defaultPredictOptions(configuration)
+	predictDefaults.apply(defaults)
+
 	return &YOLO{
 		preProcessor: &models.YOLOPreProcess{
-			InputShape: inputShape,
-			ImageUtils: &imageUtils,
+			InputShape:    inputShape,
+			ImageUtils:    &imageUtils,
+			Letterbox:     configuration.Letterbox,
+			Normalization: configuration.Normalization,
 		},
 		engine:        engine,
 		postProcessor: postProcessor,
 		inputShape:    inputShape,
 		outputShape:   outputShape,
+		batchSize:     max(int(configuration.InputShape[0]), 1),
+		letterbox:     configuration.Letterbox,
+		normalization: configuration.Normalization,
+		classes:       configuration.Classes,
 		version:       configuration.Version,
+		defaults:      predictDefaults,
+		name:          strings.TrimSuffix(filepath.Base(configuration.ModelPath), filepath.Ext(configuration.ModelPath)),
 	}, nil
 }
 
-func (yo *YOLO) Predict(img image.Image,
-	scoreThreshold, nmsThreshold float32,
-) ([]utils.BoundingBox, error) {
+// Predict detects objects in img using the model defaults overridden by
+// opts.
+func (yo *YOLO) Predict(img image.Image, opts ...PredictOption) ([]utils.BoundingBox, error) {
+	options := yo.defaults
+	options.apply(opts)
+	return yo.PredictWithOptions(img, options)
+}
 
-	originalWidth := img.Bounds().Canon().Dx()
-	originalHeight := img.Bounds().Canon().Dy()
+// Defaults returns the prediction options the model was constructed with.
+func (yo *YOLO) Defaults() PredictOptions {
+	return yo.defaults
+}
+
+// PredictWithOptions detects objects in img using options as given, with
+// per-class thresholds, class filters and limits. Start from Defaults to
+// keep the model settings.
+func (yo *YOLO) PredictWithOptions(img image.Image, options PredictOptions) ([]utils.BoundingBox, error) {
+	img, offset, ok := cropROI(img, options.ROI)
+	if !ok {
+		return []utils.BoundingBox{}, nil
+	}
 
+	place := translation(offset)
+	batches, err := yo.predictBatch([]image.Image{img}, options.postProcessOptions(), []func(*utils.BoundingBox){place})
+	if err != nil {
+		return nil, err
+	}
+
+	boxes := batches[0]
+	for i := range boxes {
+		place(&boxes[i])
+	}
+	return boxes, nil
+}
+
+// PredictRaw detects objects in a raw frame, such as the output of a
+// video decoder, reading buf in place instead of going through an image
+// type. stride is the number of bytes per row of the first plane.
+func (yo *YOLO) PredictRaw(buf []byte, width, height, stride int, format PixelFormat, opts ...PredictOption) ([]utils.BoundingBox, error) {
+	img, err := utils.NewRawImage(buf, width, height, stride, format)
+	if err != nil {
+		return nil, fmt.Errorf("error reading raw frame: %w", err)
+	}
+	return yo.Predict(img, opts...)
+}
+
+// cropROI crops img to roi and returns the offset of the crop within img.
+// It reports false when roi does not overlap the image.
+func cropROI(img image.Image, roi image.Rectangle) (image.Image, image.Point, bool) {
+	if roi.Empty() {
+		return img, image.Point{}, true
+	}
+
+	roi = roi.Intersect(img.Bounds())
+	if roi.Empty() {
+		return nil, image.Point{}, false
+	}
+	return utils.SubImage(img, roi), roi.Min.Sub(img.Bounds().Min), true
+}
+
+// translation returns a placement moving boxes by offset.
+func translation(offset image.Point) func(*utils.BoundingBox) {
+	return func(box *utils.BoundingBox) {
+		box.Translate(float32(offset.X), float32(offset.Y))
+	}
+}
+
+// batchInput is a preprocessed batch ready to be copied into the engine.
+type batchInput struct {
+	data       []float32
+	sizes      []image.Point
+	transforms []utils.Transform
+}
+
+// predictBatch runs the model on imgs, filling the batch dimension of the
+// input tensor, and returns the detections of every image in its own
+// coordinates. placements map the boxes of each image into the frame the
+// exclusion masks are given in.
+func (yo *YOLO) predictBatch(imgs []image.Image,
+	options *models.PostProcessOptions,
+	placements []func(*utils.BoundingBox),
+) ([][]utils.BoundingBox, error) {
+
+	yo.timings = Timings{}
+	results := make([][]utils.BoundingBox, 0, len(imgs))
+	for start := 0; start < len(imgs); start += yo.batchSize {
+		started := time.Now()
+		end := min(start+yo.batchSize, len(imgs))
+		input := yo.prepare(imgs[start:end], yo.engine.InputData())
+		yo.timings.Preprocess += time.Since(started)
+		boxes, err := yo.infer(input, options, placements[start:end])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, boxes...)
+	}
+	return results, nil
+}
+
+// prepare preprocesses up to batchSize images into one input tensor. The
+// pixels are written into data, usually the engine's own input buffer, and
+// the batch reuses the model's scratch input. A nil data allocates a batch
+// that outlives the next call.
+func (yo *YOLO) prepare(batch []image.Image, data []float32) *batchInput {
 	channelSize := yo.inputShape * yo.inputShape
-	totalSize := channelSize * 3
+	imageSize := channelSize * 3
+
+	input := &yo.scratch
+	if data == nil {
+		input = &batchInput{data: make([]float32, imageSize*yo.batchSize)}
+	} else {
+		input.data = data
+	}
+	input.sizes = slices.Grow(input.sizes[:0], len(batch))[:len(batch)]
+	input.transforms = slices.Grow(input.transforms[:0], len(batch))[:len(batch)]
 
-	inputData := make([]float32, totalSize)
+	for b, img := range batch {
+		dst := input.data[b*imageSize : (b+1)*imageSize]
+		input.sizes[b] = img.Bounds().Canon().Size()
+		input.transforms[b] = yo.preProcessor.PreProcess(img, &dst)
+	}
+	// Slots left over from a larger previous batch are cleared.
+	clear(input.data[len(batch)*imageSize:])
+	return input
+}
+
+// infer runs the engine on a prepared batch and post-processes every item
+// with its placement. The input is only read, so it can be shared by models
+// with the same input shape.
+func (yo *YOLO) infer(input *batchInput,
+	options *models.PostProcessOptions,
+	placements []func(*utils.BoundingBox),
+) ([][]utils.BoundingBox, error) {
 
-	scale, dw, dh := yo.preProcessor.PreProcess(img, &inputData)
-	yo.engine.SetInput(&inputData)
+	started := time.Now()
+	yo.engine.SetInput(&input.data)
 
 	err := yo.engine.Run()
 	if err != nil {
 		return nil, fmt.Errorf("error running ORT session: %s", err)
 	}
+	yo.timings.Inference += time.Since(started)
+	started = time.Now()
 
-	boxes := yo.postProcessor.PostProcess(yo.engine.GetOutput(),
-		originalWidth,
-		originalHeight,
-		scoreThreshold,
-		nmsThreshold,
-		scale, dw, dh,
-	)
+	outputs := yo.engine.GetOutputs()
+	results := make([][]utils.BoundingBox, len(input.sizes))
+	for b, size := range input.sizes {
+		itemOptions := *options
+		itemOptions.Placement = placements[b]
+		results[b] = yo.postProcessor.PostProcess(batchOutputs(outputs, b, yo.batchSize),
+			size.X,
+			size.Y,
+			input.transforms[b],
+			&itemOptions,
+		)
+	}
+	yo.timings.Postprocess += time.Since(started)
+	return results, nil
+}
 
-	return boxes, nil
+// sharesInput reports whether a batch prepared by yo can be fed to other.
+func (yo *YOLO) sharesInput(other *YOLO) bool {
+	return yo.inputShape == other.inputShape && yo.batchSize == other.batchSize &&
+		reflect.DeepEqual(yo.letterbox, other.letterbox) && yo.normalization == other.normalization
+}
+
+// batchOutputs returns the part of every output that belongs to batch
+// item b.
+func batchOutputs(outputs [][]float32, b, batchSize int) [][]float32 {
+	if batchSize == 1 {
+		return outputs
+	}
+
+	item := make([][]float32, len(outputs))
+	for i, output := range outputs {
+		size := len(output) / batchSize
+		item[i] = output[b*size : (b+1)*size]
+	}
+	return item
 }
 
 func (yo *YOLO) Destroy() {